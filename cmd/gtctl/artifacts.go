@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// refreshFlag is the flag to refresh the cached versions and chart index of artifacts.
+const refreshFlag = "refresh"
+
+func NewArtifactsCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "artifacts",
+		Short: "Manage the artifacts of GreptimeDB",
+		Long:  `Manage the artifacts of GreptimeDB, such as bundling them for offline environments`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewBundleArtifactsCommand(l))
+	cmd.AddCommand(NewImportArtifactsCommand(l))
+
+	return cmd
+}
+
+// artifactsOptions returns the options of artifacts manager that set in command line.
+func artifactsOptions(cmd *cobra.Command) []artifacts.Option {
+	refresh, _ := cmd.Flags().GetBool(refreshFlag)
+	opts := []artifacts.Option{artifacts.WithRefresh(refresh)}
+
+	// The mirror config and the cache are in the working directory of gtctl.
+	if mm, err := newMetadataManager(cmd); err == nil {
+		opts = append(opts, artifacts.WithWorkingDir(mm.GetWorkingDir()))
+	}
+
+	return opts
+}
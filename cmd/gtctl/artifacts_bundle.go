@@ -0,0 +1,101 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/bundle"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type artifactsBundleCliOptions struct {
+	GreptimeVersion        string
+	EtcdVersion            string
+	Charts                 []string
+	OS                     []string
+	Arch                   []string
+	Output                 string
+	UseGreptimeCNArtifacts bool
+}
+
+func NewBundleArtifactsCommand(l logger.Logger) *cobra.Command {
+	var options artifactsBundleCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Bundle the artifacts into a tarball for offline environments",
+		Long:  `Bundle the binaries and charts into a tarball, which can be imported by 'gtctl artifacts import' in offline environments`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := options.toBundleOptions()
+			if err != nil {
+				return err
+			}
+			opts.ArtifactsOptions = artifactsOptions(cmd)
+
+			manifest, err := bundle.Create(context.Background(), l, opts)
+			if err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Bundled %d artifacts into '%s'", len(manifest.Artifacts), logger.Bold(opts.Output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.GreptimeVersion, "greptime", artifacts.LatestVersionTag, "The version of greptime binary, skip it if it's empty.")
+	cmd.Flags().StringVar(&options.EtcdVersion, "etcd", artifacts.DefaultEtcdBinVersion, "The version of etcd binary, skip it if it's empty.")
+	cmd.Flags().StringSliceVar(&options.Charts, "charts", nil, "The charts to bundle in the format of 'name[:version]', use the latest version if the version is not specified.")
+	cmd.Flags().StringSliceVar(&options.OS, "os", []string{runtime.GOOS}, "The target operating systems of binaries.")
+	cmd.Flags().StringSliceVar(&options.Arch, "arch", []string{runtime.GOARCH}, "The target architectures of binaries.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "gtctl-bundle.tar.gz", "The path of the bundle file.")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(charts and binaries).")
+
+	return cmd
+}
+
+func (o *artifactsBundleCliOptions) toBundleOptions() (*bundle.Options, error) {
+	opts := &bundle.Options{
+		GreptimeVersion: o.GreptimeVersion,
+		EtcdVersion:     o.EtcdVersion,
+		FromCNRegion:    o.UseGreptimeCNArtifacts,
+		Output:          o.Output,
+	}
+
+	for _, chart := range o.Charts {
+		name, version, _ := strings.Cut(chart, ":")
+		if len(name) == 0 {
+			return nil, fmt.Errorf("invalid chart '%s', expected 'name[:version]'", chart)
+		}
+		opts.Charts = append(opts.Charts, bundle.Chart{Name: name, Version: version})
+	}
+
+	for _, goos := range o.OS {
+		for _, arch := range o.Arch {
+			opts.Platforms = append(opts.Platforms, bundle.Platform{OS: goos, Arch: arch})
+		}
+	}
+
+	return opts, nil
+}
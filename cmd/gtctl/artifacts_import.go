@@ -0,0 +1,53 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/bundle"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func NewImportArtifactsCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Import the artifacts from a bundle",
+		Long:  `Import the artifacts from a bundle created by 'gtctl artifacts bundle', so that they can be used without network`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mm, err := newMetadataManager(cmd)
+			if err != nil {
+				return err
+			}
+
+			manifest, err := bundle.Import(l, args[0], mm, bundle.Platform{OS: runtime.GOOS, Arch: runtime.GOARCH})
+			if err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Imported %d artifacts from '%s'", len(manifest.Artifacts), logger.Bold(args[0]))
+			l.V(0).Infof("💡 Specify the versions of imported artifacts explicitly when creating clusters without network, " +
+				"since the latest version can't be resolved offline.")
+
+			return nil
+		},
+	}
+
+	return cmd
+}
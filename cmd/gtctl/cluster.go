@@ -30,6 +30,9 @@ func NewClusterCommand(l logger.Logger) *cobra.Command {
 		Use:   "cluster",
 		Short: "Manage GreptimeDB cluster",
 		Long:  `Manage GreptimeDB cluster in Kubernetes`,
+
+		// The clusters are operated in Kubernetes unless '--bare-metal' is set.
+		Annotations: map[string]string{kubernetesAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := cmd.Help(); err != nil {
 				return err
@@ -40,8 +43,12 @@ func NewClusterCommand(l logger.Logger) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCreateClusterCommand(l))
+	cmd.AddCommand(NewApplyClusterCommand(l))
+	cmd.AddCommand(NewValuesClusterCommand(l))
 	cmd.AddCommand(NewDeleteClusterCommand(l))
 	cmd.AddCommand(NewScaleClusterCommand(l))
+	cmd.AddCommand(NewBackupClusterCommand(l))
+	cmd.AddCommand(NewRestoreClusterCommand(l))
 	cmd.AddCommand(NewGetClusterCommand(l))
 	cmd.AddCommand(NewListClustersCommand(l))
 	cmd.AddCommand(NewConnectCommand(l))
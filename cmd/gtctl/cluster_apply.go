@@ -0,0 +1,158 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+)
+
+type clusterApplyCliOptions struct {
+	SpecFile string
+	Timeout  int
+	DryRun   bool
+	Set      config.SetValues
+}
+
+func NewApplyClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterApplyCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply the spec file to an existing GreptimeDB cluster",
+		Long:  `Apply the spec file to an existing GreptimeDB cluster in Kubernetes`,
+		RunE: withHistory(l, func(cmd *cobra.Command, args []string) error {
+			if len(options.SpecFile) == 0 {
+				return fmt.Errorf("spec file should be set")
+			}
+
+			var (
+				ctx    = context.Background()
+				cancel context.CancelFunc
+			)
+
+			if options.Timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Second)
+				defer cancel()
+			}
+			ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			spec, err := config.LoadKubernetesClusterSpec(options.SpecFile)
+			if err != nil {
+				return err
+			}
+			clusterName, err := clusterNameFromSpec(args, spec)
+			if err != nil {
+				return err
+			}
+
+			if err = options.Set.Parse(); err != nil {
+				return err
+			}
+
+			spinner, err := status.NewSpinner()
+			if err != nil {
+				return err
+			}
+
+			mm, err := newMetadataManager(cmd)
+			if err != nil {
+				return err
+			}
+			if !options.DryRun {
+				lock, err := lockCluster(l, mm, metadata.ClusterModeKubernetes, spec.Metadata.Namespace, clusterName, cmd.CommandPath())
+				if err != nil {
+					return err
+				}
+				defer lock.Unlock()
+			}
+
+			record, err := mm.GetClusterRecord(metadata.ClusterModeKubernetes, spec.Metadata.Namespace, clusterName)
+			if err != nil {
+				return err
+			}
+			// The cluster is registered as Running after it's applied, e.g. the cluster that is being deleted can't be applied.
+			if record != nil && !options.DryRun && !record.State.CanRegister(metadata.ClusterStateRunning) {
+				return fmt.Errorf("cluster '%s' in namespace '%s' is %s, it can't be applied", clusterName, spec.Metadata.Namespace, record.State)
+			}
+
+			kubeconfig, kubeContext := registeredKubeConfig(cmd, record)
+			cluster, err := kubernetes.NewCluster(l,
+				kubernetes.WithKubeConfig(kubeconfig, kubeContext),
+				kubernetes.WithHomeDir(homeDir(cmd)),
+				kubernetes.WithArtifactsOptions(artifactsOptions(cmd)...),
+				kubernetes.WithDryRun(options.DryRun),
+				kubernetes.WithTimeout(time.Duration(options.Timeout)*time.Second))
+			if err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Applying GreptimeDB cluster '%s' in namespace '%s'", logger.Bold(clusterName), logger.Bold(spec.Metadata.Namespace))
+
+			applyOptions := newCreateOptionsFromSpec(spec, &options.Set, spinner)
+			historyOf(cmd).setCluster(metadata.ClusterModeKubernetes, spec.Metadata.Namespace, clusterName, kubernetesClusterVersions(applyOptions))
+			if err = cluster.Apply(ctx, applyOptions); err != nil {
+				if record != nil && !options.DryRun {
+					setClusterState(l, mm, record, metadata.ClusterStateFailed, err.Error())
+				}
+				return err
+			}
+			if options.DryRun {
+				return nil
+			}
+
+			// The versions are resolved after the cluster is applied.
+			versions := kubernetesClusterVersions(applyOptions)
+			if kc, ok := cluster.(*kubernetes.Cluster); ok {
+				versions = kc.ChartVersions()
+			}
+			historyOf(cmd).setCluster(metadata.ClusterModeKubernetes, spec.Metadata.Namespace, clusterName, versions)
+
+			// The cluster that created before the registry exists is registered after it's applied.
+			return mm.RegisterCluster(&metadata.ClusterRecord{
+				Name:        clusterName,
+				Mode:        metadata.ClusterModeKubernetes,
+				Namespace:   spec.Metadata.Namespace,
+				Kubeconfig:  kubeconfig,
+				KubeContext: kubeContext,
+				Versions:    versions,
+				Options:     changedFlags(cmd),
+				State:       metadata.ClusterStateRunning,
+				Reason:      fmt.Sprintf("Applied the spec file '%s'", options.SpecFile),
+			})
+		}),
+	}
+
+	cmd.Flags().StringVarP(&options.SpecFile, "file", "f", "", "The spec file of the greptimedb cluster in Kubernetes.")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Output the manifests without applying them.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 600, "Timeout in seconds for the command to complete, -1 means no timeout, default is 10 min.")
+	addSetValuesFlags(cmd, &options.Set)
+
+	return cmd
+}
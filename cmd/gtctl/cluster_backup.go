@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+type clusterBackupOptions struct {
+	Output string
+
+	// The options for backing up GreptimeDB cluster in bare-metal.
+	BareMetal bool
+}
+
+func NewBackupClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterBackupOptions
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Backup a GreptimeDB cluster",
+		Long:  `Backup the data and metadata of a stopped GreptimeDB cluster on bare-metal, the backup can be restored by 'gtctl cluster restore'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			if !options.BareMetal {
+				return fmt.Errorf("only the cluster on bare-metal can be backed up, please set '--bare-metal'")
+			}
+
+			clusterName := args[0]
+			mm, err := newMetadataManager(cmd)
+			if err != nil {
+				return err
+			}
+
+			// The cluster is locked so that it can't be started or deleted while it's being backed up.
+			lock, err := lockCluster(l, mm, metadata.ClusterModeBareMetal, "", clusterName, cmd.CommandPath())
+			if err != nil {
+				return err
+			}
+			defer lock.Unlock()
+
+			cluster, err := baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(), baremetal.WithHomeDir(homeDir(cmd)))
+			if err != nil {
+				return err
+			}
+
+			output := options.Output
+			if len(output) == 0 {
+				output = fmt.Sprintf("%s-%s.tar.gz", clusterName, time.Now().Format("20060102150405"))
+			}
+
+			return cluster.Backup(context.TODO(), &opt.BackupOptions{
+				Name:   clusterName,
+				Output: output,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The path of the backup file, it should be a '.tar.gz' file, use '<name>-<timestamp>.tar.gz' if it's empty.")
+	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Backup the greptimedb cluster on bare-metal environment.")
+
+	return cmd
+}
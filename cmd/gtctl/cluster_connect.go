@@ -36,9 +36,10 @@ func NewConnectCommand(l logger.Logger) *cobra.Command {
 	var options clusterConnectCliOptions
 
 	cmd := &cobra.Command{
-		Use:   "connect",
-		Short: "Connect to a GreptimeDB cluster",
-		Long:  `Connect to a GreptimeDB cluster`,
+		Use:         "connect",
+		Short:       "Connect to a GreptimeDB cluster",
+		Long:        `Connect to a GreptimeDB cluster`,
+		Annotations: map[string]string{readOnlyAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
@@ -50,7 +51,7 @@ func NewConnectCommand(l logger.Logger) *cobra.Command {
 				protocol    opt.ConnectProtocol
 			)
 
-			cluster, err := kubernetes.NewCluster(l)
+			cluster, err := kubernetes.NewCluster(l, kubernetes.WithKubeConfig(kubeConfigFlags(cmd)), kubernetes.WithHomeDir(homeDir(cmd)))
 			if err != nil {
 				return err
 			}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -25,13 +26,16 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v3"
 
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
 	"github.com/GreptimeTeam/gtctl/pkg/config"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 	"github.com/GreptimeTeam/gtctl/pkg/status"
 )
 
@@ -45,29 +49,57 @@ type clusterCreateCliOptions struct {
 	StorageRetainPolicy            string
 	GreptimeDBChartVersion         string
 	GreptimeDBOperatorChartVersion string
+	GreptimeDBChart                string
+	GreptimeDBOperatorChart        string
+	EtcdChart                      string
 	ImageRegistry                  string
 	EtcdEndpoint                   string
 	EtcdChartVersion               string
 	EtcdStorageClassName           string
 	EtcdStorageSize                string
 	EtcdClusterSize                string
+	Kubeconfig                     string
+	KubeContext                    string
 
-	// Values files that set in command line.
-	GreptimeDBClusterValuesFile  string
-	EtcdClusterValuesFile        string
-	GreptimeDBOperatorValuesFile string
+	// SpecFile is the declarative spec of the cluster in Kubernetes.
+	// If it's set, the Kubernetes related flags will be ignored.
+	SpecFile string
+
+	// Values files that set in command line, the latter will override the former.
+	GreptimeDBClusterValuesFiles  []string
+	EtcdClusterValuesFiles        []string
+	GreptimeDBOperatorValuesFiles []string
 
 	// The options for deploying GreptimeDBCluster in bare-metal.
 	BareMetal          bool
 	Config             string
 	GreptimeBinVersion string
+	Artifact           string
 	EnableCache        bool
 
+	// RestoreFrom is the backup that the data of cluster on bare-metal is restored from before the cluster is started,
+	// and RestoreConfig is the config of the restored cluster, it takes the place of Config.
+	RestoreFrom   string
+	RestoreConfig *config.BareMetalClusterConfig
+
 	// Common options.
+	HomeDir string
 	Timeout int
 	DryRun  bool
 	Set     config.SetValues
 
+	// Operation is the command that creates the cluster, it's recorded in the lock of cluster.
+	Operation string
+
+	// ArtifactsOptions is the options of artifacts manager, such as refreshing the cached versions.
+	ArtifactsOptions []artifacts.Option
+
+	// Flags is the flags that set in command line, which are recorded in the cluster registry.
+	Flags map[string]string
+
+	// History records the creation in the operation history.
+	History *historyRecorder
+
 	// If UseGreptimeCNArtifacts is true, the creation will download the artifacts(charts and binaries) from 'downloads.greptime.cn'.
 	// Also, it will use ACR registry for charts images.
 	UseGreptimeCNArtifacts bool
@@ -79,48 +111,44 @@ func NewCreateClusterCommand(l logger.Logger) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a GreptimeDB cluster",
-		Long:  `Create a GreptimeDB cluster`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		Long:  `Create a GreptimeDB cluster, the cluster in Kubernetes can also be created from the spec file by '-f'`,
+		RunE: withHistory(l, func(cmd *cobra.Command, args []string) error {
+			options.Kubeconfig, options.KubeContext = kubeConfigFlags(cmd)
+			options.HomeDir = homeDir(cmd)
+			options.Operation = cmd.CommandPath()
+			options.ArtifactsOptions = artifactsOptions(cmd)
+			options.Flags = changedFlags(cmd)
+			options.History = historyOf(cmd)
+			if len(options.SpecFile) > 0 {
+				if err := checkSpecFlags(l, cmd, options.SpecFile); err != nil {
+					return err
+				}
+			}
 			return NewCluster(args, &options, l)
-		},
+		}),
 	}
 
-	cmd.Flags().StringVar(&options.OperatorNamespace, "operator-namespace", "default", "The namespace of deploying greptimedb-operator.")
-	cmd.Flags().StringVar(&options.StorageClassName, "storage-class-name", "null", "Datanode storage class name.")
-	cmd.Flags().StringVar(&options.StorageSize, "storage-size", "10Gi", "Datanode persistent volume size.")
-	cmd.Flags().StringVar(&options.StorageRetainPolicy, "retain-policy", "Retain", "Datanode pvc retain policy.")
-	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
 	cmd.Flags().BoolVar(&options.DryRun, "dry-run", false, "Output the manifests without applying them.")
 	cmd.Flags().IntVar(&options.Timeout, "timeout", 600, "Timeout in seconds for the command to complete, -1 means no timeout, default is 10 min.")
-	cmd.Flags().StringArrayVar(&options.Set.RawConfig, "set", []string{}, "set values on the command line for greptimedb cluster, etcd and operator (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
-	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "greptimedb-chart-version", "", "The greptimedb helm chart version, use latest version if not specified.")
-	cmd.Flags().StringVar(&options.GreptimeDBOperatorChartVersion, "greptimedb-operator-chart-version", "", "The greptimedb-operator helm chart version, use latest version if not specified.")
-	cmd.Flags().StringVar(&options.EtcdChartVersion, "etcd-chart-version", "", "The greptimedb-etcd helm chart version, use latest version if not specified.")
-	cmd.Flags().StringVar(&options.ImageRegistry, "image-registry", "", "The image registry.")
-	cmd.Flags().StringVar(&options.EtcdNamespace, "etcd-namespace", "default", "The namespace of etcd cluster.")
-	cmd.Flags().StringVar(&options.EtcdStorageClassName, "etcd-storage-class-name", "null", "The etcd storage class name.")
-	cmd.Flags().StringVar(&options.EtcdStorageSize, "etcd-storage-size", "10Gi", "the etcd persistent volume size.")
-	cmd.Flags().StringVar(&options.EtcdClusterSize, "etcd-cluster-size", "1", "the etcd cluster size.")
 	cmd.Flags().BoolVar(&options.BareMetal, "bare-metal", false, "Deploy the greptimedb cluster on bare-metal environment.")
-	cmd.Flags().StringVar(&options.GreptimeBinVersion, "greptime-bin-version", "", "The version of greptime binary(can be override by config file).")
+	cmd.Flags().StringVar(&options.GreptimeBinVersion, "greptime-bin-version", "", "The version of greptime binary(can be override by config file), it can be a specific version, a channel('latest', 'stable' or 'nightly') or a semantic version range such as '~0.4'.")
+	cmd.Flags().StringVar(&options.Artifact, "artifact", "", "The OCI reference of greptime binary, e.g. 'oci://registry.example.com/greptime:v0.4.0', it takes precedence over '--greptime-bin-version' and config file. The registry credentials are read from the docker config.")
 	cmd.Flags().StringVar(&options.Config, "config", "", "Configuration to deploy the greptimedb cluster on bare-metal environment.")
 	cmd.Flags().BoolVar(&options.EnableCache, "enable-cache", true, "If true, enable cache for downloading artifacts(charts and binaries).")
-	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(charts and binaries).")
-	cmd.Flags().StringVar(&options.GreptimeDBClusterValuesFile, "greptimedb-cluster-values-file", "", "The values file for greptimedb cluster.")
-	cmd.Flags().StringVar(&options.EtcdClusterValuesFile, "etcd-cluster-values-file", "", "The values file for etcd cluster.")
-	cmd.Flags().StringVar(&options.GreptimeDBOperatorValuesFile, "greptimedb-operator-values-file", "", "The values file for greptimedb operator.")
+
+	addKubernetesFlags(cmd, &options)
 
 	return cmd
 }
 
 // NewCluster creates a new cluster.
 func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger) error {
-	if len(args) == 0 {
+	if len(args) == 0 && len(options.SpecFile) == 0 {
 		return fmt.Errorf("cluster name should be set")
 	}
 
 	var (
-		clusterName = args[0]
+		clusterName string
 		ctx         = context.Background()
 		cancel      context.CancelFunc
 	)
@@ -142,39 +170,59 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 		return err
 	}
 
-	createOptions := &opt.CreateOptions{
-		Namespace: options.Namespace,
-		Name:      clusterName,
-		Etcd: &opt.CreateEtcdOptions{
-			ImageRegistry:          options.ImageRegistry,
-			EtcdChartVersion:       options.EtcdChartVersion,
-			EtcdStorageClassName:   options.EtcdStorageClassName,
-			EtcdStorageSize:        options.EtcdStorageSize,
-			EtcdClusterSize:        options.EtcdClusterSize,
-			ConfigValues:           options.Set.EtcdConfig,
-			UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
-			ValuesFile:             options.EtcdClusterValuesFile,
-		},
-		Operator: &opt.CreateOperatorOptions{
-			GreptimeDBOperatorChartVersion: options.GreptimeDBOperatorChartVersion,
-			ImageRegistry:                  options.ImageRegistry,
-			ConfigValues:                   options.Set.OperatorConfig,
-			UseGreptimeCNArtifacts:         options.UseGreptimeCNArtifacts,
-			ValuesFile:                     options.GreptimeDBOperatorValuesFile,
-		},
-		Cluster: &opt.CreateClusterOptions{
-			GreptimeDBChartVersion:      options.GreptimeDBChartVersion,
-			ImageRegistry:               options.ImageRegistry,
-			InitializerImageRegistry:    options.ImageRegistry,
-			DatanodeStorageClassName:    options.StorageClassName,
-			DatanodeStorageSize:         options.StorageSize,
-			DatanodeStorageRetainPolicy: options.StorageRetainPolicy,
-			EtcdEndPoints:               fmt.Sprintf("%s.%s:2379", kubernetes.EtcdClusterName(clusterName), options.EtcdNamespace),
-			ConfigValues:                options.Set.ClusterConfig,
-			UseGreptimeCNArtifacts:      options.UseGreptimeCNArtifacts,
-			ValuesFile:                  options.GreptimeDBClusterValuesFile,
-		},
-		Spinner: spinner,
+	var createOptions *opt.CreateOptions
+	if len(options.SpecFile) > 0 {
+		if options.BareMetal {
+			return fmt.Errorf("the spec file is only supported in Kubernetes")
+		}
+
+		spec, err := config.LoadKubernetesClusterSpec(options.SpecFile)
+		if err != nil {
+			return err
+		}
+		clusterName, err = clusterNameFromSpec(args, spec)
+		if err != nil {
+			return err
+		}
+
+		// The namespace is used to print the tips.
+		options.Namespace = spec.Metadata.Namespace
+		createOptions = newCreateOptionsFromSpec(spec, &options.Set, spinner)
+	} else {
+		clusterName = args[0]
+		createOptions = newCreateOptions(clusterName, options, spinner)
+	}
+
+	// The cluster is locked until the creation finishes, and the cluster on bare-metal is locked until it's stopped,
+	// so that the cluster with the same name will not be created, deleted or scaled at the same time.
+	var (
+		mm            metadata.Manager
+		stoppedConfig *config.BareMetalClusterConfig
+	)
+	if !options.DryRun {
+		if mm, err = metadata.New(options.HomeDir); err != nil {
+			return err
+		}
+
+		namespace := createOptions.Namespace
+		if options.BareMetal {
+			namespace = ""
+		}
+		lock, err := lockCluster(l, mm, clusterMode(options.BareMetal), namespace, clusterName, options.Operation)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
+		options.History.setCluster(clusterMode(options.BareMetal), namespace, clusterName, nil)
+
+		// The restored cluster must be a new one, otherwise the data of the existing cluster will be mixed up.
+		if len(options.RestoreFrom) > 0 {
+			if err = checkBareMetalClusterNotExist(mm, clusterName); err != nil {
+				return err
+			}
+		} else if stoppedConfig, err = checkClusterCanBeCreated(mm, clusterMode(options.BareMetal), namespace, clusterName, options); err != nil {
+			return err
+		}
 	}
 
 	var cluster opt.Operations
@@ -183,6 +231,7 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 
 		var opts []baremetal.Option
 		opts = append(opts, baremetal.WithEnableCache(options.EnableCache))
+		opts = append(opts, baremetal.WithHomeDir(options.HomeDir), baremetal.WithArtifactsOptions(options.ArtifactsOptions...))
 		if len(options.GreptimeBinVersion) > 0 {
 			opts = append(opts, baremetal.WithGreptimeVersion(options.GreptimeBinVersion))
 		}
@@ -198,6 +247,18 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 
 			opts = append(opts, baremetal.WithReplaceConfig(&cfg))
 		}
+		if options.RestoreConfig != nil {
+			opts = append(opts, baremetal.WithReplaceConfig(options.RestoreConfig))
+		}
+		if stoppedConfig != nil {
+			opts = append(opts, baremetal.WithReplaceConfig(stoppedConfig))
+		}
+		if len(options.Artifact) > 0 {
+			if !artifacts.IsOCIReference(options.Artifact) {
+				return fmt.Errorf("invalid artifact '%s', it should be an OCI reference like 'oci://registry.example.com/greptime:v0.4.0'", options.Artifact)
+			}
+			opts = append(opts, baremetal.WithGreptimeVersion(options.Artifact))
+		}
 
 		cluster, err = baremetal.NewCluster(l, clusterName, opts...)
 		if err != nil {
@@ -207,6 +268,9 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 		l.V(0).Infof("Creating GreptimeDB cluster '%s' in namespace '%s'", logger.Bold(clusterName), logger.Bold(options.Namespace))
 
 		cluster, err = kubernetes.NewCluster(l,
+			kubernetes.WithKubeConfig(options.Kubeconfig, options.KubeContext),
+			kubernetes.WithHomeDir(options.HomeDir),
+			kubernetes.WithArtifactsOptions(options.ArtifactsOptions...),
 			kubernetes.WithDryRun(options.DryRun),
 			kubernetes.WithTimeout(time.Duration(options.Timeout)*time.Second))
 		if err != nil {
@@ -214,17 +278,79 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 		}
 	}
 
+	var record *metadata.ClusterRecord
+	if !options.DryRun {
+		record = &metadata.ClusterRecord{
+			Name:    clusterName,
+			Mode:    clusterMode(options.BareMetal),
+			Options: options.Flags,
+			State:   metadata.ClusterStateCreating,
+		}
+		if len(options.RestoreFrom) > 0 {
+			record.Reason = fmt.Sprintf("Restored from the backup '%s'", options.RestoreFrom)
+		}
+		if !options.BareMetal {
+			record.Namespace = createOptions.Namespace
+			record.Kubeconfig, record.KubeContext = options.Kubeconfig, options.KubeContext
+			record.Versions = kubernetesClusterVersions(createOptions)
+		}
+		if err = mm.RegisterCluster(record); err != nil {
+			return err
+		}
+		options.History.setCluster(record.Mode, record.Namespace, clusterName, record.Versions)
+	}
+
+	if len(options.RestoreFrom) > 0 {
+		if err = cluster.Restore(ctx, &opt.RestoreOptions{Name: clusterName, Input: options.RestoreFrom}); err != nil {
+			if record != nil {
+				setClusterState(l, mm, record, metadata.ClusterStateFailed, fmt.Sprintf("Failed to restore: %v", err))
+			}
+			return err
+		}
+	}
+
 	if err = cluster.Create(ctx, createOptions); err != nil {
+		if record != nil {
+			setClusterState(l, mm, record, metadata.ClusterStateFailed, err.Error())
+		}
 		return err
 	}
 
+	if record != nil {
+		// The versions are recorded again since they are resolved after the cluster is created.
+		switch c := cluster.(type) {
+		case *baremetal.Cluster:
+			record.Versions = bareMetalClusterVersions(c.Config())
+		case *kubernetes.Cluster:
+			record.Versions = c.ChartVersions()
+		}
+		record.State = metadata.ClusterStateRunning
+		if err = mm.RegisterCluster(record); err != nil {
+			return err
+		}
+		options.History.setCluster(record.Mode, record.Namespace, clusterName, record.Versions)
+	}
+
 	if !options.DryRun {
 		printTips(l, clusterName, options)
 	}
 
 	if options.BareMetal {
+		// The cluster on bare-metal runs until it's stopped, so the creation is recorded once it's running.
+		options.History.record(nil)
+
 		bm, _ := cluster.(*baremetal.Cluster)
-		if err = bm.Wait(ctx, false); err != nil {
+		err = bm.Wait(ctx, false)
+
+		// The stop of the cluster is recorded as another operation, which fails if the cluster crashed.
+		stopErr := err
+		if stopErr == nil && record != nil {
+			if r, _ := mm.GetClusterRecord(record.Mode, record.Namespace, clusterName); r != nil && r.State == metadata.ClusterStateFailed {
+				stopErr = errors.New(r.Reason)
+			}
+		}
+		options.History.recordStop(stopErr)
+		if err != nil {
 			return err
 		}
 	}
@@ -232,6 +358,242 @@ func NewCluster(args []string, options *clusterCreateCliOptions, l logger.Logger
 	return nil
 }
 
+// checkClusterCanBeCreated returns error if the registered cluster is not failed or stopped, so that the cluster
+// that is running or being operated is not replaced. The stopped cluster on bare-metal is started again with its kept
+// config, which is returned, and it's not allowed to be overwritten by the config or versions in command line.
+func checkClusterCanBeCreated(mm metadata.Manager, mode metadata.ClusterMode, namespace, name string,
+	options *clusterCreateCliOptions) (*config.BareMetalClusterConfig, error) {
+	record, err := mm.GetClusterRecord(mode, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.State == metadata.ClusterStateFailed {
+		return nil, nil
+	}
+	if record.State != metadata.ClusterStateStopped {
+		return nil, fmt.Errorf("cluster '%s' already exists and it's %s", name, record.State)
+	}
+	if mode != metadata.ClusterModeBareMetal {
+		return nil, nil
+	}
+
+	mm.AllocateClusterScopeDirs(name)
+	configPath := mm.GetClusterScopeDirs().ConfigPath
+	if len(options.Config) > 0 || len(options.GreptimeBinVersion) > 0 || len(options.Artifact) > 0 {
+		return nil, fmt.Errorf("cluster '%s' is stopped and its config is kept in %s, delete it before creating it with another config or version", name, configPath)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config of stopped cluster '%s': %v", name, err)
+	}
+	var cluster config.BareMetalClusterMetadata
+	if err = yaml.Unmarshal(data, &cluster); err != nil {
+		return nil, fmt.Errorf("failed to parse the config of stopped cluster '%s': %v", name, err)
+	}
+	if cluster.Config == nil {
+		return nil, fmt.Errorf("no config of stopped cluster '%s' in %s", name, configPath)
+	}
+
+	return cluster.Config, nil
+}
+
+// addKubernetesFlags adds the flags that used to render the charts of cluster in Kubernetes.
+func addKubernetesFlags(cmd *cobra.Command, options *clusterCreateCliOptions) {
+	cmd.Flags().StringVar(&options.OperatorNamespace, "operator-namespace", "default", "The namespace of deploying greptimedb-operator.")
+	cmd.Flags().StringVar(&options.StorageClassName, "storage-class-name", "null", "Datanode storage class name.")
+	cmd.Flags().StringVar(&options.StorageSize, "storage-size", "10Gi", "Datanode persistent volume size.")
+	cmd.Flags().StringVar(&options.StorageRetainPolicy, "retain-policy", "Retain", "Datanode pvc retain policy.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
+	addSetValuesFlags(cmd, &options.Set)
+	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "greptimedb-chart-version", "", "The greptimedb helm chart version, use latest version if not specified. It can also be 'stable' or a semantic version range such as '~0.1'.")
+	cmd.Flags().StringVar(&options.GreptimeDBOperatorChartVersion, "greptimedb-operator-chart-version", "", "The greptimedb-operator helm chart version, use latest version if not specified.")
+	cmd.Flags().StringVar(&options.EtcdChartVersion, "etcd-chart-version", "", "The greptimedb-etcd helm chart version, use latest version if not specified.")
+	cmd.Flags().StringVar(&options.GreptimeDBChart, "greptimedb-chart", "", "The path of local greptimedb chart directory or packaged chart, it takes precedence over '--greptimedb-chart-version'.")
+	cmd.Flags().StringVar(&options.GreptimeDBOperatorChart, "greptimedb-operator-chart", "", "The path of local greptimedb-operator chart directory or packaged chart, it takes precedence over '--greptimedb-operator-chart-version'.")
+	cmd.Flags().StringVar(&options.EtcdChart, "etcd-chart", "", "The path of local etcd chart directory or packaged chart, it takes precedence over '--etcd-chart-version'.")
+	cmd.Flags().StringVar(&options.ImageRegistry, "image-registry", "", "The image registry.")
+	cmd.Flags().StringVar(&options.EtcdNamespace, "etcd-namespace", "default", "The namespace of etcd cluster.")
+	cmd.Flags().StringVar(&options.EtcdStorageClassName, "etcd-storage-class-name", "null", "The etcd storage class name.")
+	cmd.Flags().StringVar(&options.EtcdStorageSize, "etcd-storage-size", "10Gi", "the etcd persistent volume size.")
+	cmd.Flags().StringVar(&options.EtcdClusterSize, "etcd-cluster-size", "1", "the etcd cluster size.")
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(charts and binaries).")
+	cmd.Flags().StringArrayVar(&options.GreptimeDBClusterValuesFiles, "greptimedb-cluster-values-file", []string{}, "The values file for greptimedb cluster (can specify multiple, the latter will override the former).")
+	cmd.Flags().StringArrayVar(&options.EtcdClusterValuesFiles, "etcd-cluster-values-file", []string{}, "The values file for etcd cluster (can specify multiple, the latter will override the former).")
+	cmd.Flags().StringArrayVar(&options.GreptimeDBOperatorValuesFiles, "greptimedb-operator-values-file", []string{}, "The values file for greptimedb operator (can specify multiple, the latter will override the former).")
+	cmd.Flags().StringVarP(&options.SpecFile, "file", "f", "", "The spec file of the greptimedb cluster in Kubernetes, it replaces the Kubernetes related flags except the '--set' ones.")
+}
+
+// checkSpecFlags rejects the Kubernetes flags that set in command line if the cluster is created from the spec file,
+// because the spec replaces them. The ones that set by the context are ignored with a warning.
+func checkSpecFlags(l logger.Logger, cmd *cobra.Command, specFile string) error {
+	// The values that set on the command line are applied on top of the spec.
+	kept := &cobra.Command{}
+	addSetValuesFlags(kept, &config.SetValues{})
+
+	replaced := &cobra.Command{}
+	addKubernetesFlags(replaced, &clusterCreateCliOptions{})
+
+	var conflicts, ignored []string
+	replaced.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "file" || kept.Flags().Lookup(f.Name) != nil {
+			return
+		}
+		flag := cmd.Flags().Lookup(f.Name)
+		switch {
+		case flag == nil:
+		case flag.Changed:
+			conflicts = append(conflicts, "--"+f.Name)
+		case flag.Value.String() != flag.DefValue:
+			ignored = append(ignored, "--"+f.Name)
+		}
+	})
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("the flags %v can't be used with the spec file '%s', please set them in the spec file instead", conflicts, specFile)
+	}
+	if len(ignored) > 0 {
+		l.Warnf("The flags %v that set by the context are ignored, the spec file '%s' takes precedence", ignored, specFile)
+	}
+
+	return nil
+}
+
+// addSetValuesFlags adds the flags that set values of charts on the command line.
+func addSetValuesFlags(cmd *cobra.Command, set *config.SetValues) {
+	cmd.Flags().StringArrayVar(&set.RawConfig, "set", []string{}, "set values on the command line for greptimedb cluster, etcd and operator (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
+	cmd.Flags().StringArrayVar(&set.RawStringConfig, "set-string", []string{}, "set STRING values on the command line for greptimedb cluster, etcd and operator (can specify multiple or separate values with commas: eg. cluster.key1=val1,etcd.key2=val2).")
+	cmd.Flags().StringArrayVar(&set.RawFileConfig, "set-file", []string{}, "set values from respective files on the command line for greptimedb cluster, etcd and operator (can specify multiple or separate values with commas: eg. cluster.key1=path1,etcd.key2=path2).")
+	cmd.Flags().StringArrayVar(&set.RawJSONConfig, "set-json", []string{}, "set JSON values on the command line for greptimedb cluster, etcd and operator (can specify multiple or separate values with commas: eg. cluster.key1=jsonval1,etcd.key2=jsonval2).")
+}
+
+// newCreateOptions creates the options of creating cluster from the command line flags.
+func newCreateOptions(clusterName string, options *clusterCreateCliOptions, spinner *status.Spinner) *opt.CreateOptions {
+	return &opt.CreateOptions{
+		Namespace: options.Namespace,
+		Name:      clusterName,
+		Etcd: &opt.CreateEtcdOptions{
+			ImageRegistry:          options.ImageRegistry,
+			EtcdChartVersion:       options.EtcdChartVersion,
+			EtcdChartPath:          options.EtcdChart,
+			EtcdStorageClassName:   options.EtcdStorageClassName,
+			EtcdStorageSize:        options.EtcdStorageSize,
+			EtcdClusterSize:        options.EtcdClusterSize,
+			Namespace:              options.EtcdNamespace,
+			ConfigValues:           options.Set.EtcdConfig,
+			StringConfigValues:     options.Set.EtcdStringConfig,
+			FileConfigValues:       options.Set.EtcdFileConfig,
+			JSONConfigValues:       options.Set.EtcdJSONConfig,
+			UseGreptimeCNArtifacts: options.UseGreptimeCNArtifacts,
+			ValuesFiles:            options.EtcdClusterValuesFiles,
+		},
+		Operator: &opt.CreateOperatorOptions{
+			GreptimeDBOperatorChartVersion: options.GreptimeDBOperatorChartVersion,
+			GreptimeDBOperatorChartPath:    options.GreptimeDBOperatorChart,
+			Namespace:                      options.OperatorNamespace,
+			ImageRegistry:                  options.ImageRegistry,
+			ConfigValues:                   options.Set.OperatorConfig,
+			StringConfigValues:             options.Set.OperatorStringConfig,
+			FileConfigValues:               options.Set.OperatorFileConfig,
+			JSONConfigValues:               options.Set.OperatorJSONConfig,
+			UseGreptimeCNArtifacts:         options.UseGreptimeCNArtifacts,
+			ValuesFiles:                    options.GreptimeDBOperatorValuesFiles,
+		},
+		Cluster: &opt.CreateClusterOptions{
+			GreptimeDBChartVersion:      options.GreptimeDBChartVersion,
+			GreptimeDBChartPath:         options.GreptimeDBChart,
+			ImageRegistry:               options.ImageRegistry,
+			InitializerImageRegistry:    options.ImageRegistry,
+			DatanodeStorageClassName:    options.StorageClassName,
+			DatanodeStorageSize:         options.StorageSize,
+			DatanodeStorageRetainPolicy: options.StorageRetainPolicy,
+			ConfigValues:                options.Set.ClusterConfig,
+			StringConfigValues:          options.Set.ClusterStringConfig,
+			FileConfigValues:            options.Set.ClusterFileConfig,
+			JSONConfigValues:            options.Set.ClusterJSONConfig,
+			UseGreptimeCNArtifacts:      options.UseGreptimeCNArtifacts,
+			ValuesFiles:                 options.GreptimeDBClusterValuesFiles,
+		},
+		Spinner: spinner,
+	}
+}
+
+// newCreateOptionsFromSpec creates the options of creating cluster from the spec file.
+// The values that set in command line will still be applied.
+func newCreateOptionsFromSpec(spec *config.KubernetesClusterSpec, set *config.SetValues, spinner *status.Spinner) *opt.CreateOptions {
+	var (
+		clusterName = spec.Metadata.Name
+		body        = spec.Spec
+	)
+
+	// The image registry of each chart will fall back to the global one.
+	registry := func(registry string) string {
+		if len(registry) > 0 {
+			return registry
+		}
+		return body.ImageRegistry
+	}
+
+	return &opt.CreateOptions{
+		Namespace: spec.Metadata.Namespace,
+		Name:      clusterName,
+		Etcd: &opt.CreateEtcdOptions{
+			ImageRegistry:          registry(body.Etcd.ImageRegistry),
+			EtcdChartVersion:       body.Etcd.ChartVersion,
+			EtcdChartPath:          body.Etcd.Chart,
+			EtcdStorageClassName:   body.Etcd.StorageClassName,
+			EtcdStorageSize:        body.Etcd.StorageSize,
+			EtcdClusterSize:        body.Etcd.ClusterSize,
+			Namespace:              body.Etcd.Namespace,
+			ConfigValues:           set.EtcdConfig,
+			StringConfigValues:     set.EtcdStringConfig,
+			FileConfigValues:       set.EtcdFileConfig,
+			JSONConfigValues:       set.EtcdJSONConfig,
+			UseGreptimeCNArtifacts: body.UseGreptimeCNArtifacts,
+			ValuesFiles:            body.Etcd.ValuesFiles,
+			Values:                 body.Etcd.Values,
+		},
+		Operator: &opt.CreateOperatorOptions{
+			GreptimeDBOperatorChartVersion: body.Operator.ChartVersion,
+			GreptimeDBOperatorChartPath:    body.Operator.Chart,
+			Namespace:                      body.Operator.Namespace,
+			ImageRegistry:                  registry(body.Operator.ImageRegistry),
+			ConfigValues:                   set.OperatorConfig,
+			StringConfigValues:             set.OperatorStringConfig,
+			FileConfigValues:               set.OperatorFileConfig,
+			JSONConfigValues:               set.OperatorJSONConfig,
+			UseGreptimeCNArtifacts:         body.UseGreptimeCNArtifacts,
+			ValuesFiles:                    body.Operator.ValuesFiles,
+			Values:                         body.Operator.Values,
+		},
+		Cluster: &opt.CreateClusterOptions{
+			GreptimeDBChartVersion:      body.Cluster.ChartVersion,
+			GreptimeDBChartPath:         body.Cluster.Chart,
+			ImageRegistry:               registry(body.Cluster.ImageRegistry),
+			InitializerImageRegistry:    registry(body.Cluster.ImageRegistry),
+			DatanodeStorageClassName:    body.Cluster.StorageClassName,
+			DatanodeStorageSize:         body.Cluster.StorageSize,
+			DatanodeStorageRetainPolicy: body.Cluster.StorageRetainPolicy,
+			ConfigValues:                set.ClusterConfig,
+			StringConfigValues:          set.ClusterStringConfig,
+			FileConfigValues:            set.ClusterFileConfig,
+			JSONConfigValues:            set.ClusterJSONConfig,
+			UseGreptimeCNArtifacts:      body.UseGreptimeCNArtifacts,
+			ValuesFiles:                 body.Cluster.ValuesFiles,
+			Values:                      body.Cluster.Values,
+		},
+		Spinner: spinner,
+	}
+}
+
+// clusterNameFromSpec returns the cluster name in spec. If the name is also set in args, they should be the same.
+func clusterNameFromSpec(args []string, spec *config.KubernetesClusterSpec) (string, error) {
+	if len(args) > 0 && args[0] != spec.Metadata.Name {
+		return "", fmt.Errorf("cluster name '%s' does not match the name '%s' in spec", args[0], spec.Metadata.Name)
+	}
+	return spec.Metadata.Name, nil
+}
+
 func printTips(l logger.Logger, clusterName string, options *clusterCreateCliOptions) {
 	l.V(0).Infof("\nNow you can use the following commands to access the GreptimeDB cluster:")
 	l.V(0).Infof("\n%s", logger.Bold("MySQL >"))
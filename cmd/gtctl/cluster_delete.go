@@ -26,6 +26,7 @@ import (
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 )
 
 type clusterDeleteOptions struct {
@@ -43,7 +44,7 @@ func NewDeleteClusterCommand(l logger.Logger) *cobra.Command {
 		Use:   "delete",
 		Short: "Delete a GreptimeDB cluster",
 		Long:  `Delete a GreptimeDB cluster`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: withHistory(l, func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
 			}
@@ -51,14 +52,38 @@ func NewDeleteClusterCommand(l logger.Logger) *cobra.Command {
 			clusterName := args[0]
 			var (
 				cluster opt.Operations
-				err     error
 				ctx     = context.TODO()
 			)
 
+			mm, err := newMetadataManager(cmd)
+			if err != nil {
+				return err
+			}
+			record, err := lookupCluster(mm, clusterName, options.Namespace, options.BareMetal)
+			if err != nil {
+				return err
+			}
+
+			mode, namespace := metadata.ClusterModeKubernetes, options.Namespace
+			if options.BareMetal {
+				mode, namespace = metadata.ClusterModeBareMetal, ""
+			}
+			lock, err := lockCluster(l, mm, mode, namespace, clusterName, cmd.CommandPath())
+			if err != nil {
+				return err
+			}
+			defer lock.Unlock()
+
+			var versions map[string]string
+			if record != nil {
+				versions = record.Versions
+			}
+			historyOf(cmd).setCluster(mode, namespace, clusterName, versions)
+
 			if options.BareMetal {
-				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(), baremetal.WithHomeDir(homeDir(cmd)))
 			} else {
-				cluster, err = kubernetes.NewCluster(l)
+				cluster, err = kubernetes.NewCluster(l, kubernetes.WithKubeConfig(registeredKubeConfig(cmd, record)), kubernetes.WithHomeDir(homeDir(cmd)))
 			}
 			if err != nil {
 				return err
@@ -68,8 +93,21 @@ func NewDeleteClusterCommand(l logger.Logger) *cobra.Command {
 				Namespace: options.Namespace,
 				Name:      clusterName,
 			}
-			return cluster.Delete(ctx, deleteOptions)
-		},
+			deleting := record != nil && setClusterState(l, mm, record, metadata.ClusterStateDeleting, "")
+			if err = cluster.Delete(ctx, deleteOptions); err != nil {
+				if deleting {
+					// The cluster that failed to be deleted is back to its previous state.
+					state := record.State
+					if !metadata.ClusterStateDeleting.CanTransit(state) {
+						state = metadata.ClusterStateFailed
+					}
+					setClusterState(l, mm, record, state, fmt.Sprintf("Failed to delete: %v", err))
+				}
+				return err
+			}
+
+			return mm.UnregisterCluster(mode, namespace, clusterName)
+		}),
 	}
 
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "default", "Namespace of GreptimeDB cluster.")
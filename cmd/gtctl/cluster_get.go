@@ -43,9 +43,10 @@ func NewGetClusterCommand(l logger.Logger) *cobra.Command {
 	table := tablewriter.NewWriter(os.Stdout)
 
 	cmd := &cobra.Command{
-		Use:   "get",
-		Short: "Get GreptimeDB cluster",
-		Long:  `Get GreptimeDB cluster`,
+		Use:         "get",
+		Short:       "Get GreptimeDB cluster",
+		Long:        `Get GreptimeDB cluster`,
+		Annotations: map[string]string{readOnlyAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
@@ -58,10 +59,19 @@ func NewGetClusterCommand(l logger.Logger) *cobra.Command {
 				clusterName = args[0]
 			)
 
+			mm, err := newMetadataManager(cmd)
+			if err != nil {
+				return err
+			}
+			record, err := lookupCluster(mm, clusterName, options.Namespace, options.BareMetal)
+			if err != nil {
+				return err
+			}
+
 			if options.BareMetal {
-				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs())
+				cluster, err = baremetal.NewCluster(l, clusterName, baremetal.WithCreateNoDirs(), baremetal.WithHomeDir(homeDir(cmd)))
 			} else {
-				cluster, err = kubernetes.NewCluster(l)
+				cluster, err = kubernetes.NewCluster(l, kubernetes.WithKubeConfig(registeredKubeConfig(cmd, record)), kubernetes.WithHomeDir(homeDir(cmd)))
 			}
 			if err != nil {
 				return err
@@ -72,7 +82,21 @@ func NewGetClusterCommand(l logger.Logger) *cobra.Command {
 				Name:      clusterName,
 				Table:     table,
 			}
-			return cluster.Get(ctx, getOptions)
+			if err = cluster.Get(ctx, getOptions); err != nil {
+				return err
+			}
+
+			if record != nil {
+				l.V(0).Infof("Cluster '%s' is managed by gtctl, versions: %s", clusterName, formatVersions(record.Versions))
+				l.V(0).Infof("STATE: %s, since %s", logger.Bold(string(record.State)), record.LastTransitionTime)
+				if len(record.Reason) > 0 {
+					l.V(0).Infof("REASON: %s", record.Reason)
+				}
+			} else {
+				l.V(0).Infof("Cluster '%s' is not created by gtctl on this machine", clusterName)
+			}
+
+			return nil
 		},
 	}
 
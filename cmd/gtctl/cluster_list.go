@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/olekukonko/tablewriter"
@@ -28,28 +29,56 @@ import (
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
 
+type clusterListCliOptions struct {
+	// FromKubernetes lists the clusters in Kubernetes instead of the ones in the cluster registry.
+	FromKubernetes bool
+}
+
 func NewListClustersCommand(l logger.Logger) *cobra.Command {
+	var options clusterListCliOptions
+
 	table := tablewriter.NewWriter(os.Stdout)
 
 	cmd := &cobra.Command{
-		Use:   "list",
-		Short: "List all GreptimeDB clusters",
-		Long:  `List all GreptimeDB clusters`,
+		Use:         "list",
+		Short:       "List all GreptimeDB clusters",
+		Long:        `List all GreptimeDB clusters that created by gtctl on this machine, or the ones in Kubernetes with '--from-kubernetes'`,
+		Annotations: map[string]string{readOnlyAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var ctx = context.Background()
 
-			cluster, err := kubernetes.NewCluster(l)
+			if options.FromKubernetes {
+				cluster, err := kubernetes.NewCluster(l, kubernetes.WithKubeConfig(kubeConfigFlags(cmd)), kubernetes.WithHomeDir(homeDir(cmd)))
+				if err != nil {
+					return err
+				}
+
+				return cluster.List(ctx, &opt.ListOptions{
+					GetOptions: opt.GetOptions{
+						Table: table,
+					},
+				})
+			}
+
+			mm, err := newMetadataManager(cmd)
 			if err != nil {
 				return err
 			}
+			records, err := mm.ListClusterRecords()
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				return fmt.Errorf("clusters not found")
+			}
+
+			renderClusterRecords(table, records)
 
-			return cluster.List(ctx, &opt.ListOptions{
-				GetOptions: opt.GetOptions{
-					Table: table,
-				},
-			})
+			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&options.FromKubernetes, "from-kubernetes", false, "List the GreptimeDB clusters in Kubernetes, including the ones that are not created by gtctl.")
+
 	return cmd
 }
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+// clusterMode returns the mode of cluster in the cluster registry.
+func clusterMode(bareMetal bool) metadata.ClusterMode {
+	if bareMetal {
+		return metadata.ClusterModeBareMetal
+	}
+	return metadata.ClusterModeKubernetes
+}
+
+// lookupCluster returns the registered cluster on bare-metal if '--bare-metal' is set, otherwise the one in Kubernetes.
+// It returns nil if the cluster is not registered. The cluster in Kubernetes may be created before the registry
+// existed, so it returns error instead of the cluster on bare-metal with the same name, which must not be operated
+// by mistake.
+func lookupCluster(mm metadata.Manager, name, namespace string, bareMetal bool) (*metadata.ClusterRecord, error) {
+	if bareMetal {
+		return mm.GetClusterRecord(metadata.ClusterModeBareMetal, "", name)
+	}
+
+	record, err := mm.GetClusterRecord(metadata.ClusterModeKubernetes, namespace, name)
+	if err != nil || record != nil {
+		return record, err
+	}
+
+	bareMetalRecord, err := mm.GetClusterRecord(metadata.ClusterModeBareMetal, "", name)
+	if err != nil {
+		return nil, err
+	}
+	if bareMetalRecord != nil {
+		return nil, fmt.Errorf("cluster '%s' is not registered in namespace '%s' but on bare-metal, please set '--bare-metal' to operate it", name, namespace)
+	}
+
+	return nil, nil
+}
+
+// registeredKubeConfig returns the kubeconfig and context of the registered cluster if they are not set in command line.
+func registeredKubeConfig(cmd *cobra.Command, record *metadata.ClusterRecord) (string, string) {
+	kubeconfig, kubeContext := kubeConfigFlags(cmd)
+	if record != nil && len(kubeconfig) == 0 && len(kubeContext) == 0 {
+		return record.Kubeconfig, record.KubeContext
+	}
+	return kubeconfig, kubeContext
+}
+
+// setClusterState records the state of the registered cluster and returns true if it's recorded.
+// The failure of recording is only logged because it should not fail the operation on the cluster.
+func setClusterState(l logger.Logger, mm metadata.Manager, record *metadata.ClusterRecord, state metadata.ClusterState, reason string) bool {
+	if err := mm.SetClusterState(record.Mode, record.Namespace, record.Name, state, reason); err != nil {
+		l.V(3).Infof("failed to record the state of cluster '%s': %v", record.Name, err)
+		return false
+	}
+	return true
+}
+
+// lockCluster acquires the lock of the cluster for the operation, the stale lock left by a dead process is reclaimed.
+func lockCluster(l logger.Logger, mm metadata.Manager, mode metadata.ClusterMode, namespace, name, operation string) (*metadata.ClusterLock, error) {
+	lock, err := mm.LockCluster(mode, namespace, name, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner := lock.Reclaimed; owner != nil {
+		l.Warnf("Reclaimed the stale lock of cluster '%s' that was held by dead process %d ('%s') since %s",
+			name, owner.Pid, owner.Operation, owner.Since.Format(time.RFC3339))
+	}
+
+	return lock, nil
+}
+
+// changedFlags returns the flags that set in command line, which are recorded as the options of cluster.
+func changedFlags(cmd *cobra.Command) map[string]string {
+	flags := make(map[string]string)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
+
+// kubernetesClusterVersions returns the versions of charts that the cluster in Kubernetes is going to use.
+// They are the specifiers like 'latest' before the charts are loaded, which are replaced by the resolved
+// versions after the cluster is created. The local chart takes the place of version, and the empty version
+// means the latest one.
+func kubernetesClusterVersions(options *opt.CreateOptions) map[string]string {
+	chartVersion := func(version, path string) string {
+		if len(path) > 0 {
+			return path
+		}
+		if len(version) == 0 {
+			return artifacts.LatestVersionTag
+		}
+		return version
+	}
+
+	versions := make(map[string]string)
+	if options.Operator != nil {
+		versions[artifacts.GreptimeDBOperatorChartName] = chartVersion(options.Operator.GreptimeDBOperatorChartVersion, options.Operator.GreptimeDBOperatorChartPath)
+	}
+	if options.Etcd != nil {
+		version := options.Etcd.EtcdChartVersion
+		if len(version) == 0 {
+			version = artifacts.DefaultEtcdChartVersion
+		}
+		versions[artifacts.EtcdChartName] = chartVersion(version, options.Etcd.EtcdChartPath)
+	}
+	if options.Cluster != nil {
+		versions[artifacts.GreptimeDBClusterChartName] = chartVersion(options.Cluster.GreptimeDBChartVersion, options.Cluster.GreptimeDBChartPath)
+	}
+	return versions
+}
+
+// bareMetalClusterVersions returns the versions of binaries that the cluster on bare-metal uses.
+// The local binary takes the place of version.
+func bareMetalClusterVersions(cfg *config.BareMetalClusterConfig) map[string]string {
+	artifactVersion := func(artifact *config.Artifact) string {
+		if len(artifact.Local) > 0 {
+			return artifact.Local
+		}
+		return artifact.Version
+	}
+
+	versions := make(map[string]string)
+	if cfg.Cluster != nil && cfg.Cluster.Artifact != nil {
+		versions[artifacts.GreptimeBinName] = artifactVersion(cfg.Cluster.Artifact)
+	}
+	if cfg.Etcd != nil && cfg.Etcd.Artifact != nil {
+		versions[artifacts.EtcdBinName] = artifactVersion(cfg.Etcd.Artifact)
+	}
+	return versions
+}
+
+// formatVersions formats the versions like 'etcd=9.2.0,greptimedb-cluster=0.1.2' in the order of names.
+func formatVersions(versions map[string]string) string {
+	var pairs []string
+	for name, version := range versions {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, version))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func renderClusterRecords(table *tablewriter.Table, records []*metadata.ClusterRecord) {
+	configListView(table)
+
+	table.SetHeader([]string{"Name", "Mode", "Namespace", "Kube Context", "Versions", "State", "Reason", "Last Transition", "Creation Date"})
+	defer table.Render()
+
+	for _, record := range records {
+		table.Append([]string{
+			record.Name,
+			string(record.Mode),
+			record.Namespace,
+			record.KubeContext,
+			formatVersions(record.Versions),
+			string(record.State),
+			record.Reason,
+			record.LastTransitionTime.String(),
+			record.CreationDate.String(),
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/baremetal"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+type clusterRestoreOptions struct {
+	Name        string
+	Config      string
+	Timeout     int
+	EnableCache bool
+}
+
+func NewRestoreClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterRestoreOptions
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a GreptimeDB cluster from a backup",
+		Long: `Restore a new GreptimeDB cluster on bare-metal from a backup created by 'gtctl cluster backup', and start it.
+The addresses of the backed-up cluster can be replaced by the config set by '--config'. The addresses that are in use,
+conflict with each other or whose hosts are not on this machine are replaced with the available ones automatically.
+
+The registrations of metasrv and datanodes in the restored etcd are kept. The node IDs of datanodes are the same as
+the backup, so their registrations are refreshed with the new addresses by the heartbeats once they are started, and
+the stale leases of the backed-up cluster expire.`,
+		Args: cobra.ExactArgs(1),
+		RunE: withHistory(l, func(cmd *cobra.Command, args []string) error {
+			backup := args[0]
+			manifest, err := baremetal.ReadBackupManifest(backup)
+			if err != nil {
+				return err
+			}
+
+			clusterName := options.Name
+			if len(clusterName) == 0 {
+				clusterName = manifest.Name
+			}
+
+			cfg := manifest.Config
+			if len(options.Config) > 0 {
+				if cfg, err = restoredClusterConfig(manifest.Config, options.Config); err != nil {
+					return err
+				}
+			}
+			replacements, err := baremetal.ReplaceUnavailableAddrs(cfg)
+			if err != nil {
+				return err
+			}
+			for _, replacement := range replacements {
+				l.V(0).Infof("Replaced the unavailable address of %s", replacement)
+			}
+
+			return NewCluster([]string{clusterName}, &clusterCreateCliOptions{
+				BareMetal:        true,
+				EnableCache:      options.EnableCache,
+				RestoreFrom:      backup,
+				RestoreConfig:    cfg,
+				HomeDir:          homeDir(cmd),
+				Timeout:          options.Timeout,
+				Operation:        cmd.CommandPath(),
+				ArtifactsOptions: artifactsOptions(cmd),
+				Flags:            changedFlags(cmd),
+				History:          historyOf(cmd),
+			}, l)
+		}),
+	}
+
+	cmd.Flags().StringVar(&options.Name, "name", "", "The name of the restored cluster, use the name of the backed-up cluster if it's empty.")
+	cmd.Flags().StringVar(&options.Config, "config", "", "The configuration of the restored cluster on bare-metal, which replaces the addresses of components in backup.")
+	cmd.Flags().IntVar(&options.Timeout, "timeout", 600, "Timeout in seconds for the command to complete, -1 means no timeout, default is 10 min.")
+	cmd.Flags().BoolVar(&options.EnableCache, "enable-cache", true, "If true, enable cache for downloading artifacts(binaries).")
+
+	return cmd
+}
+
+// restoredClusterConfig returns the config in file that replaces the components config in backup.
+// The artifacts in backup are kept unless they are set in file, and the replicas of datanode should be the same
+// since the data of each datanode is restored.
+func restoredClusterConfig(backupConfig *config.BareMetalClusterConfig, file string) (*config.BareMetalClusterConfig, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config.BareMetalClusterConfig
+	if err = yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Cluster == nil || cfg.Cluster.Datanode == nil {
+		return nil, fmt.Errorf("invalid config '%s': missing the config of cluster components", file)
+	}
+
+	if cfg.Cluster.Artifact == nil {
+		cfg.Cluster.Artifact = backupConfig.Cluster.Artifact
+	}
+	if cfg.Etcd == nil {
+		cfg.Etcd = backupConfig.Etcd
+	}
+
+	if want := backupConfig.Cluster.Datanode.Replicas; cfg.Cluster.Datanode.Replicas != want {
+		return nil, fmt.Errorf("the replicas of datanode in config '%s' should be %d as the backup", file, want)
+	}
+
+	return &cfg, nil
+}
+
+// checkBareMetalClusterNotExist returns error if the cluster on bare-metal is registered or its directory exists.
+func checkBareMetalClusterNotExist(mm metadata.Manager, name string) error {
+	record, err := mm.GetClusterRecord(metadata.ClusterModeBareMetal, "", name)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		return fmt.Errorf("cluster '%s' already exists", name)
+	}
+
+	mm.AllocateClusterScopeDirs(name)
+	if _, err = os.Stat(mm.GetClusterScopeDirs().BaseDir); err == nil {
+		return fmt.Errorf("cluster '%s' already exists in %s", name, mm.GetClusterScopeDirs().BaseDir)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
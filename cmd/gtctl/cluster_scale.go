@@ -27,6 +27,7 @@ import (
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 )
 
 type clusterScaleCliOptions struct {
@@ -61,7 +62,7 @@ func NewScaleClusterCommand(l logger.Logger) *cobra.Command {
 		Use:   "scale",
 		Short: "Scale GreptimeDB cluster",
 		Long:  `Scale GreptimeDB cluster`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: withHistory(l, func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("cluster name should be set")
 			}
@@ -80,7 +81,27 @@ func NewScaleClusterCommand(l logger.Logger) *cobra.Command {
 				defer cancel()
 			}
 
-			cluster, err := kubernetes.NewCluster(l)
+			mm, err := newMetadataManager(cmd)
+			if err != nil {
+				return err
+			}
+			lock, err := lockCluster(l, mm, metadata.ClusterModeKubernetes, options.Namespace, args[0], cmd.CommandPath())
+			if err != nil {
+				return err
+			}
+			defer lock.Unlock()
+
+			record, err := mm.GetClusterRecord(metadata.ClusterModeKubernetes, options.Namespace, args[0])
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				historyOf(cmd).setCluster(record.Mode, record.Namespace, record.Name, record.Versions)
+			} else {
+				historyOf(cmd).setCluster(metadata.ClusterModeKubernetes, options.Namespace, args[0], nil)
+			}
+
+			cluster, err := kubernetes.NewCluster(l, kubernetes.WithKubeConfig(registeredKubeConfig(cmd, record)), kubernetes.WithHomeDir(homeDir(cmd)))
 			if err != nil {
 				return err
 			}
@@ -91,8 +112,21 @@ func NewScaleClusterCommand(l logger.Logger) *cobra.Command {
 				NewReplicas:   options.Replicas,
 				ComponentType: greptimedbclusterv1alpha1.ComponentKind(options.ComponentType),
 			}
-			return cluster.Scale(ctx, scaleOptions)
-		},
+			if err = cluster.Scale(ctx, scaleOptions); err != nil {
+				// The cluster is not affected if the scale fails before it's updated, e.g. the cluster is not found.
+				if record != nil && scaleOptions.Updated {
+					setClusterState(l, mm, record, metadata.ClusterStateDegraded, fmt.Sprintf("Failed to scale %s: %v", options.ComponentType, err))
+				}
+				return err
+			}
+
+			if record != nil {
+				setClusterState(l, mm, record, metadata.ClusterStateRunning,
+					fmt.Sprintf("Scaled %s from %d to %d replicas", options.ComponentType, scaleOptions.OldReplicas, scaleOptions.NewReplicas))
+			}
+
+			return nil
+		}),
 	}
 
 	cmd.Flags().StringVarP(&options.ComponentType, "component", "c", "", "Component of GreptimeDB cluster, can be 'frontend', 'datanode' and 'meta'.")
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/cluster/kubernetes"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/helm"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	chartCluster  = "cluster"
+	chartEtcd     = "etcd"
+	chartOperator = "operator"
+)
+
+type clusterValuesCliOptions struct {
+	clusterCreateCliOptions
+
+	Chart string
+}
+
+func NewValuesClusterCommand(l logger.Logger) *cobra.Command {
+	var options clusterValuesCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "values",
+		Short: "Show the values of the chart that used to create a GreptimeDB cluster",
+		Long: `Show the merged values of the chart that used to create a GreptimeDB cluster in Kubernetes with the same flags,
+each key is annotated with its source: default, file, spec or flag. It works offline, the default values of chart are
+read from the local chart or the cached one. It will not contact the Kubernetes cluster`,
+		Annotations: map[string]string{readOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && len(options.SpecFile) == 0 {
+				return fmt.Errorf("cluster name should be set")
+			}
+
+			if err := options.Set.Parse(); err != nil {
+				return err
+			}
+
+			var (
+				createOptions *opt.CreateOptions
+				specFields    map[string]bool
+			)
+			if len(options.SpecFile) > 0 {
+				spec, err := config.LoadKubernetesClusterSpec(options.SpecFile)
+				if err != nil {
+					return err
+				}
+				if _, err = clusterNameFromSpec(args, spec); err != nil {
+					return err
+				}
+				createOptions = newCreateOptionsFromSpec(spec, &options.Set, nil)
+
+				if specFields, err = explicitSpecFields(options.SpecFile, options.Chart); err != nil {
+					return err
+				}
+			} else {
+				createOptions = newCreateOptions(args[0], &options.clusterCreateCliOptions, nil)
+			}
+
+			var (
+				loadOptions *helm.LoadOptions
+				err         error
+			)
+			switch options.Chart {
+			case chartCluster:
+				loadOptions, err = kubernetes.ClusterLoadOptions(createOptions)
+			case chartEtcd:
+				loadOptions, err = kubernetes.EtcdLoadOptions(createOptions)
+			case chartOperator:
+				loadOptions, err = kubernetes.OperatorLoadOptions(createOptions)
+			default:
+				return fmt.Errorf("unknown chart '%s', should be one of '%s', '%s' and '%s'", options.Chart, chartCluster, chartEtcd, chartOperator)
+			}
+			if err != nil {
+				return err
+			}
+			loadOptions.ValuesOptionsSources = valuesOptionsSources(cmd, options.Chart, specFields)
+
+			loader, err := helm.NewLoader(l, helm.WithHomeDir(homeDir(cmd)), helm.WithArtifactsOptions(artifactsOptions(cmd)...))
+			if err != nil {
+				return err
+			}
+
+			values, err := loader.LoadValues(context.Background(), loadOptions)
+			if err != nil {
+				return err
+			}
+
+			output, err := values.OutputAnnotatedValues()
+			if err != nil {
+				return err
+			}
+			l.V(0).Info(string(output))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Chart, "chart", chartCluster, "The chart to show values, can be 'cluster', 'etcd' or 'operator'.")
+	addKubernetesFlags(cmd, &options.clusterCreateCliOptions)
+
+	return cmd
+}
+
+// chartFieldFlags are the flags that set the fields of the options to render each chart, keyed by the field name.
+var chartFieldFlags = map[string]map[string][]string{
+	chartCluster: {
+		"ImageRegistry":               {"image-registry"},
+		"InitializerImageRegistry":    {"image-registry"},
+		"DatanodeStorageClassName":    {"storage-class-name"},
+		"DatanodeStorageSize":         {"storage-size"},
+		"DatanodeStorageRetainPolicy": {"retain-policy"},
+	},
+	chartEtcd: {
+		"ImageRegistry":        {"image-registry"},
+		"EtcdClusterSize":      {"etcd-cluster-size"},
+		"EtcdStorageClassName": {"etcd-storage-class-name"},
+		"EtcdStorageSize":      {"etcd-storage-size"},
+	},
+	chartOperator: {
+		"ImageRegistry": {"image-registry"},
+	},
+}
+
+// setValuesFieldFlags are the flags that set the fields of the options to render all the charts, keyed by the field name.
+// The CN artifacts replace the image registry by the config values.
+var setValuesFieldFlags = map[string][]string{
+	"ConfigValues":       {"set", "use-greptime-cn-artifacts"},
+	"StringConfigValues": {"set-string"},
+	"FileConfigValues":   {"set-file"},
+	"JSONConfigValues":   {"set-json"},
+}
+
+// valuesOptionsSources returns the source of each field of the options to render the chart. The fields that set
+// by the changed flags come from 'flag', and the fields that set explicitly in spec file come from 'spec'.
+// The others are the defaults of gtctl, which are not included.
+func valuesOptionsSources(cmd *cobra.Command, chart string, specFields map[string]bool) map[string]string {
+	sources := make(map[string]string)
+	for field := range specFields {
+		sources[field] = helm.ValueSourceSpec
+	}
+
+	fieldFlags := setValuesFieldFlags
+	if specFields == nil {
+		// The flags of charts are replaced by the spec file.
+		fieldFlags = make(map[string][]string)
+		for field, flags := range setValuesFieldFlags {
+			fieldFlags[field] = flags
+		}
+		for field, flags := range chartFieldFlags[chart] {
+			fieldFlags[field] = flags
+		}
+	}
+
+	for field, flags := range fieldFlags {
+		for _, flag := range flags {
+			if cmd.Flags().Changed(flag) {
+				sources[field] = helm.ValueSourceFlag
+			}
+		}
+	}
+
+	return sources
+}
+
+// explicitSpecFields returns the fields of the options to render the chart that set explicitly in the spec file,
+// the defaults of spec are not included.
+func explicitSpecFields(specFile, chart string) (map[string]bool, error) {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unlike config.LoadKubernetesClusterSpec, the spec is not filled with the defaults.
+	var spec config.KubernetesClusterSpec
+	if err = yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid cluster spec '%s': %v", specFile, err)
+	}
+
+	fields := make(map[string]bool)
+	set := func(field, value string) {
+		if len(value) > 0 {
+			fields[field] = true
+		}
+	}
+
+	body := spec.Spec
+	if body == nil {
+		return fields, nil
+	}
+	if body.UseGreptimeCNArtifacts {
+		fields["ConfigValues"] = true
+	}
+
+	// The image registry of each chart falls back to the global one.
+	registry := len(body.ImageRegistry) > 0
+	switch chart {
+	case chartCluster:
+		if body.Cluster != nil {
+			registry = registry || len(body.Cluster.ImageRegistry) > 0
+			set("DatanodeStorageClassName", body.Cluster.StorageClassName)
+			set("DatanodeStorageSize", body.Cluster.StorageSize)
+			set("DatanodeStorageRetainPolicy", body.Cluster.StorageRetainPolicy)
+		}
+		if registry {
+			fields["ImageRegistry"] = true
+			fields["InitializerImageRegistry"] = true
+		}
+	case chartEtcd:
+		if body.Etcd != nil {
+			registry = registry || len(body.Etcd.ImageRegistry) > 0
+			set("EtcdClusterSize", body.Etcd.ClusterSize)
+			set("EtcdStorageClassName", body.Etcd.StorageClassName)
+			set("EtcdStorageSize", body.Etcd.StorageSize)
+		}
+		if registry {
+			fields["ImageRegistry"] = true
+		}
+	case chartOperator:
+		if body.Operator != nil {
+			registry = registry || len(body.Operator.ImageRegistry) > 0
+		}
+		if registry {
+			fields["ImageRegistry"] = true
+		}
+	}
+
+	return fields, nil
+}
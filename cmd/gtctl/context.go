@@ -0,0 +1,372 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+const (
+	// skipContextAnnotation is the annotation of command that should not be affected by the context.
+	skipContextAnnotation = "gtctl.greptime.io/skip-context"
+
+	// kubernetesAnnotation is the annotation of command whose subcommands may operate the clusters in Kubernetes,
+	// the kubeconfig and kube-context of context are only applied to them since the flags are inherited by all commands.
+	kubernetesAnnotation = "gtctl.greptime.io/kubernetes"
+
+	// readOnlyAnnotation is the annotation of command that changes nothing, it runs without the context
+	// if the user config is invalid.
+	readOnlyAnnotation = "gtctl.greptime.io/read-only"
+
+	contextFlag     = "context"
+	kubeconfigFlag  = "kubeconfig"
+	kubeContextFlag = "kube-context"
+)
+
+type contextSetCliOptions struct {
+	Mode                      string
+	Kubeconfig                string
+	KubeContext               string
+	Namespace                 string
+	ImageRegistry             string
+	ArtifactRegion            string
+	GreptimeVersion           string
+	GreptimeDBChartVersion    string
+	GreptimeDBOperatorVersion string
+	EtcdChartVersion          string
+}
+
+func NewContextCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:        cobra.NoArgs,
+		Use:         "context",
+		Short:       "Manage gtctl contexts",
+		Long:        `Manage the named contexts in the user config of gtctl, the flags of commands will use the settings of current context by default`,
+		Annotations: map[string]string{skipContextAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewUseContextCommand(l))
+	cmd.AddCommand(NewListContextsCommand(l))
+	cmd.AddCommand(NewSetContextCommand(l))
+
+	return cmd
+}
+
+func NewUseContextCommand(l logger.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:         "use",
+		Short:       "Set the current context",
+		Long:        `Set the current context`,
+		Annotations: map[string]string{skipContextAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("context name should be set")
+			}
+
+			path, cfg, err := loadUserConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if err = cfg.UseContext(args[0]); err != nil {
+				return err
+			}
+			if err = cfg.Save(path); err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Switched to context '%s'", logger.Bold(args[0]))
+			return nil
+		},
+	}
+}
+
+func NewListContextsCommand(l logger.Logger) *cobra.Command {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	return &cobra.Command{
+		Use:         "list",
+		Short:       "List all the contexts",
+		Long:        `List all the contexts`,
+		Annotations: map[string]string{skipContextAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, cfg, err := loadUserConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if len(cfg.Contexts) == 0 {
+				l.V(0).Infof("No contexts found, use 'gtctl context set' to create one")
+				return nil
+			}
+
+			configListView(table)
+			table.SetHeader([]string{"Current", "Name", "Mode", "Namespace", "Kube Context", "Artifact Region"})
+			for _, ctx := range cfg.Contexts {
+				current := ""
+				if ctx.Name == cfg.CurrentContext {
+					current = "*"
+				}
+				table.Append([]string{current, ctx.Name, ctx.Mode, ctx.Namespace, ctx.KubeContext, ctx.ArtifactRegion})
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+}
+
+func NewSetContextCommand(l logger.Logger) *cobra.Command {
+	var options contextSetCliOptions
+
+	cmd := &cobra.Command{
+		Use:         "set",
+		Short:       "Create or update a context",
+		Long:        `Create or update a context, only the flags that set in command line will be updated`,
+		Annotations: map[string]string{skipContextAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("context name should be set")
+			}
+
+			path, cfg, err := loadUserConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cfg.GetContext(args[0])
+			if ctx == nil {
+				ctx = &config.Context{Name: args[0]}
+			}
+
+			fields := map[string]*string{
+				"mode":                              &ctx.Mode,
+				kubeconfigFlag:                      &ctx.Kubeconfig,
+				kubeContextFlag:                     &ctx.KubeContext,
+				"namespace":                         &ctx.Namespace,
+				"image-registry":                    &ctx.ImageRegistry,
+				"artifact-region":                   &ctx.ArtifactRegion,
+				"greptime-bin-version":              &ctx.GreptimeVersion,
+				"greptimedb-chart-version":          &ctx.GreptimeDBChartVersion,
+				"greptimedb-operator-chart-version": &ctx.GreptimeDBOperatorVersion,
+				"etcd-chart-version":                &ctx.EtcdChartVersion,
+			}
+			for name, field := range fields {
+				if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+					*field = flag.Value.String()
+				}
+			}
+
+			cfg.SetContext(ctx)
+			if err = cfg.Save(path); err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Context '%s' is saved in %s", logger.Bold(ctx.Name), path)
+			return nil
+		},
+	}
+
+	// The kubeconfig and kube-context flags are inherited from the root command.
+	cmd.Flags().StringVar(&options.Mode, "mode", "", "The deployment mode of clusters, can be 'k8s' or 'bare-metal'.")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The default namespace of GreptimeDB cluster.")
+	cmd.Flags().StringVar(&options.ImageRegistry, "image-registry", "", "The image registry.")
+	cmd.Flags().StringVar(&options.ArtifactRegion, "artifact-region", "", "The region to download artifacts, can be 'global' or 'cn'.")
+	cmd.Flags().StringVar(&options.GreptimeVersion, "greptime-bin-version", "", "The default version of greptime binary.")
+	cmd.Flags().StringVar(&options.GreptimeDBChartVersion, "greptimedb-chart-version", "", "The default greptimedb helm chart version.")
+	cmd.Flags().StringVar(&options.GreptimeDBOperatorVersion, "greptimedb-operator-chart-version", "", "The default greptimedb-operator helm chart version.")
+	cmd.Flags().StringVar(&options.EtcdChartVersion, "etcd-chart-version", "", "The default etcd helm chart version.")
+
+	return cmd
+}
+
+// userConfigPath returns the path of the user config of gtctl.
+func userConfigPath(cmd *cobra.Command) (string, error) {
+	mm, err := newMetadataManager(cmd)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mm.GetWorkingDir(), config.UserConfigFile), nil
+}
+
+// loadUserConfig loads the user config and returns its path.
+func loadUserConfig(cmd *cobra.Command) (string, *config.UserConfig, error) {
+	path, err := userConfigPath(cmd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cfg, err := config.LoadUserConfig(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return path, cfg, nil
+}
+
+// applyContext sets the flags of cmd that not set in command line with the settings of context.
+// The context is selected by '--context' or the current context of user config.
+func applyContext(l logger.Logger, cmd *cobra.Command) error {
+	if cmd.Annotations[skipContextAnnotation] == "true" {
+		return nil
+	}
+
+	// The user config is loaded only if it may set the flags, so that the invalid user config
+	// doesn't break the commands that are not affected by the context.
+	name, _ := cmd.Flags().GetString(contextFlag)
+	if len(name) == 0 && !hasUnsetContextFlags(cmd) {
+		return nil
+	}
+
+	_, cfg, err := loadUserConfig(cmd)
+	if err != nil && len(name) == 0 && cmd.Annotations[readOnlyAnnotation] == "true" {
+		l.Warnf("Ignore the context: %v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx := cfg.GetCurrentContext()
+	if len(name) > 0 {
+		if ctx = cfg.GetContext(name); ctx == nil {
+			return fmt.Errorf("context '%s' is not found", name)
+		}
+	}
+
+	// The default greptime version is applied even if there is no context.
+	if err = setUnchangedFlags(cmd, map[string]string{greptimeVersionFlag: cfg.GreptimeVersion}); err != nil {
+		return fmt.Errorf("invalid greptime version in user config: %v", err)
+	}
+	if ctx == nil {
+		return nil
+	}
+
+	values := map[string]string{
+		kubeconfigFlag:                      ctx.Kubeconfig,
+		kubeContextFlag:                     ctx.KubeContext,
+		"namespace":                         ctx.Namespace,
+		"image-registry":                    ctx.ImageRegistry,
+		greptimeVersionFlag:                 ctx.GreptimeVersion,
+		"greptimedb-chart-version":          ctx.GreptimeDBChartVersion,
+		"greptimedb-operator-chart-version": ctx.GreptimeDBOperatorVersion,
+		"etcd-chart-version":                ctx.EtcdChartVersion,
+	}
+	if len(ctx.Mode) > 0 {
+		values["bare-metal"] = strconv.FormatBool(ctx.Mode == config.ModeBareMetal)
+	}
+	if len(ctx.ArtifactRegion) > 0 {
+		values["use-greptime-cn-artifacts"] = strconv.FormatBool(ctx.ArtifactRegion == config.ArtifactRegionCN)
+	}
+
+	if err = setUnchangedFlags(cmd, values); err != nil {
+		return fmt.Errorf("invalid context '%s': %v", ctx.Name, err)
+	}
+
+	return nil
+}
+
+// contextFlags are the flags that can be set by the context or the user config.
+var contextFlags = []string{
+	kubeconfigFlag,
+	kubeContextFlag,
+	"namespace",
+	"image-registry",
+	greptimeVersionFlag,
+	"greptimedb-chart-version",
+	"greptimedb-operator-chart-version",
+	"etcd-chart-version",
+	"bare-metal",
+	"use-greptime-cn-artifacts",
+}
+
+// hasUnsetContextFlags returns true if cmd has the flags that can be set by the context and not set in command line.
+func hasUnsetContextFlags(cmd *cobra.Command) bool {
+	for _, name := range contextFlags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if (name == kubeconfigFlag || name == kubeContextFlag) && !usesKubernetes(cmd) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// usesKubernetes returns true if cmd or its parents are annotated that they may operate the clusters in Kubernetes.
+func usesKubernetes(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[kubernetesAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// setUnchangedFlags sets the flags of cmd that not set in command line, the empty values are ignored.
+func setUnchangedFlags(cmd *cobra.Command, values map[string]string) error {
+	for name, value := range values {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || len(value) == 0 {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid value '%s' of '%s': %v", value, name, err)
+		}
+	}
+
+	return nil
+}
+
+// kubeConfigFlags returns the kubeconfig and the context in it that set in command line or context.
+func kubeConfigFlags(cmd *cobra.Command) (string, string) {
+	kubeconfig, _ := cmd.Flags().GetString(kubeconfigFlag)
+	kubeContext, _ := cmd.Flags().GetString(kubeContextFlag)
+	return kubeconfig, kubeContext
+}
+
+func configListView(table *tablewriter.Table) {
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/versions"
+)
+
+const greptimeVersionFlag = "greptime-bin-version"
+
+func NewGreptimeCommand(l logger.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "greptime",
+		Short: "Manage the versions of greptime binary",
+		Long:  `Manage the installed versions of greptime binary that used by the bare-metal clusters`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return err
+			}
+
+			return errors.New("subcommand is required")
+		},
+	}
+
+	cmd.AddCommand(NewInstallGreptimeCommand(l))
+	cmd.AddCommand(NewListGreptimeCommand(l))
+	cmd.AddCommand(NewUseGreptimeCommand(l))
+	cmd.AddCommand(NewPruneGreptimeCommand(l))
+	cmd.AddCommand(NewExecGreptimeCommand(l))
+
+	return cmd
+}
+
+func newVersionManager(cmd *cobra.Command, l logger.Logger) (*versions.Manager, error) {
+	am, err := artifacts.NewManager(l, artifactsOptions(cmd)...)
+	if err != nil {
+		return nil, err
+	}
+
+	mm, err := newMetadataManager(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions.NewManager(l, am, mm), nil
+}
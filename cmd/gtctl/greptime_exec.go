@@ -0,0 +1,81 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type greptimeExecCliOptions struct {
+	GreptimeBinVersion string
+}
+
+func NewExecGreptimeCommand(l logger.Logger) *cobra.Command {
+	var options greptimeExecCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "exec -- <args>",
+		Short: "Run the greptime binary",
+		Long:  `Run the selected version of greptime binary with the arguments after '--'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(options.GreptimeBinVersion) == 0 {
+				return fmt.Errorf("no greptime version is selected, use 'gtctl greptime use <version>' to select one")
+			}
+
+			vm, err := newVersionManager(cmd, l)
+			if err != nil {
+				return err
+			}
+
+			installed, err := vm.Get(artifacts.GreptimeBinName, options.GreptimeBinVersion)
+			if err != nil {
+				return fmt.Errorf("%v, use 'gtctl greptime install %s' to install it", err, options.GreptimeBinVersion)
+			}
+
+			l.V(3).Infof("Running '%s' with args %v", installed.Path, args)
+			c := exec.Command(installed.Path, args...)
+			c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+			// Exit with the exit code of greptime, so that gtctl can be used in its place in scripts.
+			if err = c.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					code := exitErr.ExitCode()
+					if code < 0 {
+						// The greptime is killed by signal.
+						code = 1
+					}
+					os.Exit(code)
+				}
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.GreptimeBinVersion, greptimeVersionFlag, "", "The version of greptime binary to run, use the default version if it's empty.")
+
+	return cmd
+}
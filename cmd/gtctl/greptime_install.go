@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+	"github.com/GreptimeTeam/gtctl/pkg/versions"
+)
+
+type greptimeInstallCliOptions struct {
+	UseGreptimeCNArtifacts bool
+}
+
+func NewInstallGreptimeCommand(l logger.Logger) *cobra.Command {
+	var options greptimeInstallCliOptions
+
+	cmd := &cobra.Command{
+		Use:   "install [version]",
+		Short: "Install a version of greptime binary",
+		Long:  `Download and install a version of greptime binary, the version can be a specific version, a channel('latest', 'stable' or 'nightly'), a semantic version range such as '~0.4', or the OCI reference such as 'oci://registry.example.com/greptime:v0.4.0'. Install the latest version if the version is not specified`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version := artifacts.LatestVersionTag
+			if len(args) > 0 {
+				version = args[0]
+			}
+
+			vm, err := newVersionManager(cmd, l)
+			if err != nil {
+				return err
+			}
+
+			spinner, err := status.NewSpinner()
+			if err != nil {
+				return err
+			}
+
+			spinner.Start(fmt.Sprintf("Installing greptime '%s'...", version))
+			installed, err := vm.Install(context.Background(), artifacts.GreptimeBinName, version, &versions.InstallOptions{
+				FromCNRegion: options.UseGreptimeCNArtifacts,
+				Spinner:      spinner,
+			})
+			if err != nil {
+				spinner.Stop(false, fmt.Sprintf("Installing greptime '%s' failed", version))
+				return err
+			}
+			spinner.Stop(true, fmt.Sprintf("Installing greptime '%s' successfully 🎉", installed.Version))
+
+			l.V(0).Infof("The binary is installed in '%s'", logger.Bold(installed.Path))
+			l.V(0).Infof("💡 Use '%s' to set it as the default version.", logger.Bold(fmt.Sprintf("gtctl greptime use %s", installed.Version)))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.UseGreptimeCNArtifacts, "use-greptime-cn-artifacts", false, "If true, use greptime-cn artifacts(charts and binaries).")
+
+	return cmd
+}
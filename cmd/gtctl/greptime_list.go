@@ -0,0 +1,103 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+type greptimeListCliOptions struct {
+	Remote bool
+}
+
+func NewListGreptimeCommand(l logger.Logger) *cobra.Command {
+	var options greptimeListCliOptions
+	table := tablewriter.NewWriter(os.Stdout)
+
+	cmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List the versions of greptime binary",
+		Long:        `List the installed versions of greptime binary, or the released versions with '--remote'`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{readOnlyAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vm, err := newVersionManager(cmd, l)
+			if err != nil {
+				return err
+			}
+
+			current, _ := cmd.Flags().GetString(greptimeVersionFlag)
+			installed, err := vm.List(artifacts.GreptimeBinName)
+			if err != nil {
+				return err
+			}
+
+			configListView(table)
+			if options.Remote {
+				released, err := vm.ListRemote(context.Background(), artifacts.GreptimeBinName)
+				if err != nil {
+					return err
+				}
+
+				isInstalled := make(map[string]bool)
+				for _, v := range installed {
+					isInstalled[v.Version] = true
+				}
+
+				table.SetHeader([]string{"Version", "Installed"})
+				for _, version := range released {
+					mark := ""
+					if isInstalled[version] {
+						mark = "*"
+					}
+					table.Append([]string{version, mark})
+				}
+				table.Render()
+
+				return nil
+			}
+
+			if len(installed) == 0 {
+				l.V(0).Infof("No installed versions found, use 'gtctl greptime install' to install one")
+				return nil
+			}
+
+			table.SetHeader([]string{"Current", "Version", "Path"})
+			for _, v := range installed {
+				mark := ""
+				if v.Version == current {
+					mark = "*"
+				}
+				table.Append([]string{mark, v.Version, v.Path})
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.Remote, "remote", false, "If true, list the released versions instead of the installed versions.")
+	cmd.Flags().String(greptimeVersionFlag, "", "The version of greptime binary to mark as current, use the default version if it's empty.")
+
+	return cmd
+}
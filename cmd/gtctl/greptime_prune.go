@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func NewPruneGreptimeCommand(l logger.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:         "prune",
+		Short:       "Remove the unused versions of greptime binary",
+		Long:        `Remove the installed versions of greptime binary that are not referenced by any bare-metal cluster, the default version is retained`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipContextAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vm, err := newVersionManager(cmd, l)
+			if err != nil {
+				return err
+			}
+
+			_, cfg, err := loadUserConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Retain the default versions in user config and all contexts.
+			keep := []string{cfg.GreptimeVersion}
+			for _, ctx := range cfg.Contexts {
+				keep = append(keep, ctx.GreptimeVersion)
+			}
+
+			removed, err := vm.Prune(artifacts.GreptimeBinName, keep...)
+			if err != nil {
+				return err
+			}
+
+			if len(removed) == 0 {
+				l.V(0).Infof("No unused versions found")
+				return nil
+			}
+			l.V(0).Infof("Removed greptime versions: %s", logger.Bold(strings.Join(removed, ", ")))
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func NewUseGreptimeCommand(l logger.Logger) *cobra.Command {
+	return &cobra.Command{
+		Use:         "use <version>",
+		Short:       "Set the default version of greptime binary",
+		Long:        `Set the default version of greptime binary that used by bare-metal clusters, the version in context takes precedence over it`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{skipContextAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vm, err := newVersionManager(cmd, l)
+			if err != nil {
+				return err
+			}
+
+			// Only the installed version can be used, so that it works without network.
+			if _, err = vm.Get(artifacts.GreptimeBinName, args[0]); err != nil {
+				return fmt.Errorf("%v, use 'gtctl greptime install %s' to install it", err, args[0])
+			}
+
+			path, cfg, err := loadUserConfig(cmd)
+			if err != nil {
+				return err
+			}
+			cfg.GreptimeVersion = args[0]
+			if err = cfg.Save(path); err != nil {
+				return err
+			}
+
+			l.V(0).Infof("Switched to greptime '%s'", logger.Bold(args[0]))
+			if ctx := cfg.GetCurrentContext(); ctx != nil && len(ctx.GreptimeVersion) > 0 && ctx.GreptimeVersion != args[0] {
+				l.Warnf("The current context '%s' uses greptime '%s', which takes precedence.", ctx.Name, ctx.GreptimeVersion)
+			}
+
+			return nil
+		},
+	}
+}
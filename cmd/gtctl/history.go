@@ -0,0 +1,273 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+type historyCliOptions struct {
+	Cluster string
+	JSON    bool
+}
+
+func NewHistoryCommand(l logger.Logger) *cobra.Command {
+	var options historyCliOptions
+
+	table := tablewriter.NewWriter(os.Stdout)
+
+	cmd := &cobra.Command{
+		Use:         "history",
+		Short:       "Show the operation history",
+		Long:        `Show the history of the operations that changed the clusters, such as creating, scaling and deleting them`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipContextAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mm, err := newMetadataManager(cmd)
+			if err != nil {
+				return err
+			}
+			entries, err := mm.ListHistory(options.Cluster)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return fmt.Errorf("history not found")
+			}
+
+			if options.JSON {
+				encoder := json.NewEncoder(os.Stdout)
+				for _, entry := range entries {
+					if err = encoder.Encode(entry); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			renderHistory(table, entries)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Cluster, "cluster", "", "Only show the operations on the cluster.")
+	cmd.Flags().BoolVar(&options.JSON, "json", false, "Print the full entries in JSON lines, including the resolved options and the spec and values files of each operation.")
+
+	return cmd
+}
+
+func renderHistory(table *tablewriter.Table, entries []*metadata.HistoryEntry) {
+	configListView(table)
+
+	table.SetHeader([]string{"Time", "User", "Command", "Mode", "Namespace", "Cluster", "Versions", "Duration", "Result", "Error"})
+	defer table.Render()
+
+	for _, entry := range entries {
+		table.Append([]string{
+			entry.Timestamp.Format(time.RFC3339),
+			entry.User,
+			entry.Command,
+			string(entry.Mode),
+			entry.Namespace,
+			entry.Cluster,
+			formatVersions(entry.Versions),
+			entry.Duration,
+			string(entry.Result),
+			entry.Error,
+		})
+	}
+}
+
+// historyRecorderKey is the key of the history recorder in the context of command.
+type historyRecorderKey struct{}
+
+// historyRecorder records an operation that changes a cluster in the history.
+type historyRecorder struct {
+	logger   logger.Logger
+	cmd      *cobra.Command
+	entry    *metadata.HistoryEntry
+	recorded bool
+}
+
+// withHistory wraps the run of a command that changes a cluster, so that the operation is recorded in the history.
+// The cluster is the first argument by default, and the command can set the details by the recorder of historyOf.
+// The operation in dry-run mode is not recorded since it changes nothing.
+func withHistory(l logger.Logger, run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return run(cmd, args)
+		}
+
+		h := &historyRecorder{
+			logger: l,
+			cmd:    cmd,
+			entry: &metadata.HistoryEntry{
+				Timestamp: time.Now(),
+				User:      currentUser(),
+				Command:   cmd.CommandPath(),
+				Options:   resolvedFlags(cmd),
+				Files:     inputFiles(cmd),
+			},
+		}
+		if len(args) > 0 {
+			h.entry.Cluster = args[0]
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		cmd.SetContext(context.WithValue(ctx, historyRecorderKey{}, h))
+
+		err := run(cmd, args)
+		h.record(err)
+
+		return err
+	}
+}
+
+// historyOf returns the history recorder of the operation that the command is running, or nil if it's not recorded.
+func historyOf(cmd *cobra.Command) *historyRecorder {
+	if cmd.Context() == nil {
+		return nil
+	}
+	h, _ := cmd.Context().Value(historyRecorderKey{}).(*historyRecorder)
+	return h
+}
+
+// setCluster sets the cluster that the operation changes, and the versions that are applied if they are not nil.
+func (h *historyRecorder) setCluster(mode metadata.ClusterMode, namespace, name string, versions map[string]string) {
+	if h == nil {
+		return
+	}
+	h.entry.Mode, h.entry.Namespace, h.entry.Cluster = mode, namespace, name
+	if versions != nil {
+		h.entry.Versions = versions
+	}
+}
+
+// record appends the operation with its result to the history, the operation is only recorded once.
+// The failure of recording is only logged because it should not fail the operation on the cluster.
+func (h *historyRecorder) record(err error) {
+	if h == nil || h.recorded {
+		return
+	}
+	h.recorded = true
+
+	h.entry.Duration = time.Since(h.entry.Timestamp).Round(time.Millisecond).String()
+	h.entry.Result = metadata.HistoryResultSucceeded
+	if err != nil {
+		h.entry.Result = metadata.HistoryResultFailed
+		h.entry.Error = err.Error()
+	}
+
+	mm, merr := newMetadataManager(h.cmd)
+	if merr == nil {
+		merr = mm.AppendHistory(h.entry)
+	}
+	if merr != nil {
+		h.logger.V(3).Infof("failed to record the operation history: %v", merr)
+	}
+}
+
+// recordStop records the stop of the cluster that runs in foreground as another operation after its creation is
+// recorded, the operation fails if the cluster is stopped by the failure of its components.
+func (h *historyRecorder) recordStop(err error) {
+	if h == nil || !h.recorded {
+		return
+	}
+
+	h.entry = &metadata.HistoryEntry{
+		Timestamp: time.Now(),
+		User:      h.entry.User,
+		Command:   fmt.Sprintf("%s (stop)", h.entry.Command),
+		Mode:      h.entry.Mode,
+		Namespace: h.entry.Namespace,
+		Cluster:   h.entry.Cluster,
+		Versions:  h.entry.Versions,
+	}
+	h.recorded = false
+	h.record(err)
+}
+
+// resolvedFlags returns all the flags of cmd with the values that set in command line, context or defaults.
+func resolvedFlags(cmd *cobra.Command) map[string]string {
+	flags := make(map[string]string)
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" {
+			return
+		}
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
+
+// currentUser returns the name of the user that runs gtctl.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// inputFiles returns the contents of the spec and values files that are set in the flags of cmd, including the
+// values files in the spec. The files that can't be read are skipped, since the command fails with them.
+func inputFiles(cmd *cobra.Command) map[string]string {
+	var paths []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch {
+		case f.Name == "file":
+			paths = append(paths, f.Value.String())
+			if spec, err := config.LoadKubernetesClusterSpec(f.Value.String()); err == nil {
+				for _, values := range [][]string{spec.Spec.Cluster.ValuesFiles, spec.Spec.Etcd.ValuesFiles, spec.Spec.Operator.ValuesFiles} {
+					paths = append(paths, values...)
+				}
+			}
+		case f.Name == "config":
+			paths = append(paths, f.Value.String())
+		case strings.HasSuffix(f.Name, "values-file"):
+			values, _ := cmd.Flags().GetStringArray(f.Name)
+			paths = append(paths, values...)
+		}
+	})
+
+	files := make(map[string]string)
+	for _, path := range paths {
+		if data, err := os.ReadFile(path); err == nil {
+			files[path] = string(data)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return files
+}
@@ -24,10 +24,14 @@ import (
 	"sigs.k8s.io/kind/pkg/log"
 
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 	"github.com/GreptimeTeam/gtctl/pkg/plugins"
 	"github.com/GreptimeTeam/gtctl/pkg/version"
 )
 
+// homeFlag is the flag of the home directory of gtctl.
+const homeFlag = "home"
+
 func NewRootCommand() *cobra.Command {
 	const GtctlTextBanner = `
           __       __  __
@@ -53,25 +57,47 @@ func NewRootCommand() *cobra.Command {
 			type verboser interface {
 				SetVerbosity(log.Level)
 			}
-			if v, ok := l.(verboser); ok {
-				v.SetVerbosity(log.Level(verbosity))
-				return nil
+			v, ok := l.(verboser)
+			if !ok {
+				return fmt.Errorf("logger does not implement SetVerbosity")
 			}
+			v.SetVerbosity(log.Level(verbosity))
 
-			return fmt.Errorf("logger does not implement SetVerbosity")
+			return applyContext(l, cmd)
 		},
 	}
 
 	cmd.PersistentFlags().Int32VarP(&verbosity, "verbosity", "v", 0, "info log verbosity, higher value produces more output")
+	cmd.PersistentFlags().String(homeFlag, "", fmt.Sprintf("The home directory of gtctl, the metadata is stored in '<home>/%s', use '%s' or the home directory of current user if it's empty.", metadata.BaseDir, metadata.HomeDirEnv))
+	cmd.PersistentFlags().String(contextFlag, "", "The name of the gtctl context to use, use the current context if it's empty.")
+	cmd.PersistentFlags().String(kubeconfigFlag, "", "The path of kubeconfig, use '~/.kube/config' if it's empty.")
+	cmd.PersistentFlags().Bool(refreshFlag, false, "Refresh the cached versions and chart index of artifacts instead of using the unexpired cache.")
+	cmd.PersistentFlags().String(kubeContextFlag, "", "The context in kubeconfig to use, use the current context of kubeconfig if it's empty.")
 
 	// Add all top level subcommands.
 	cmd.AddCommand(NewVersionCommand(l))
 	cmd.AddCommand(NewClusterCommand(l))
 	cmd.AddCommand(NewPlaygroundCommand(l))
+	cmd.AddCommand(NewContextCommand(l))
+	cmd.AddCommand(NewArtifactsCommand(l))
+	cmd.AddCommand(NewGreptimeCommand(l))
+	cmd.AddCommand(NewHistoryCommand(l))
 
 	return cmd
 }
 
+// homeDir returns the home directory of gtctl that set in command line,
+// the empty dir means using 'GTCTL_HOME' or the home directory of current user.
+func homeDir(cmd *cobra.Command) string {
+	dir, _ := cmd.Flags().GetString(homeFlag)
+	return dir
+}
+
+// newMetadataManager creates the metadata manager in the home directory of gtctl.
+func newMetadataManager(cmd *cobra.Command) (metadata.Manager, error) {
+	return metadata.New(homeDir(cmd))
+}
+
 func main() {
 	pm, err := plugins.NewManager()
 	if err != nil {
@@ -28,7 +28,7 @@ func NewPlaygroundCommand(l logger.Logger) *cobra.Command {
 		Use:   "playground",
 		Short: "Starts a GreptimeDB cluster playground",
 		Long:  "Starts a GreptimeDB cluster playground in bare-metal mode",
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: withHistory(l, func(cmd *cobra.Command, args []string) error {
 			rng, err := codename.DefaultRNG()
 			if err != nil {
 				return nil
@@ -39,9 +39,14 @@ func NewPlaygroundCommand(l logger.Logger) *cobra.Command {
 				BareMetal:   true,
 				Timeout:     900, // 15min
 				EnableCache: false,
+				HomeDir:     homeDir(cmd),
+				Operation:   cmd.CommandPath(),
+				History:     historyOf(cmd),
+
+				ArtifactsOptions: artifactsOptions(cmd),
 			}
 
 			return NewCluster([]string{playgroundName}, playgroundOptions, l)
-		},
+		}),
 	}
 }
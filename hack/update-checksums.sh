@@ -0,0 +1,33 @@
+#!/usr/bin/env bash
+# Copyright 2023 Greptime Team
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Update the embedded checksum manifests of the pinned etcd versions in 'pkg/artifacts/checksums'.
+# Usage: ./hack/update-checksums.sh [etcd-version...], the default is the DefaultEtcdBinVersion.
+
+set -euo pipefail
+
+REPO_ROOT=$(git rev-parse --show-toplevel)
+CHECKSUMS_DIR="${REPO_ROOT}/pkg/artifacts/checksums"
+
+versions=("$@")
+if [ ${#versions[@]} -eq 0 ]; then
+  versions=("$(sed -n 's/.*DefaultEtcdBinVersion = "\(.*\)"/\1/p' "${REPO_ROOT}/pkg/artifacts/constants.go")")
+fi
+
+for version in "${versions[@]}"; do
+  manifest="${CHECKSUMS_DIR}/etcd-${version}.SHA256SUMS"
+  echo "Downloading the checksum manifest of etcd ${version} to ${manifest}"
+  curl -fsSL "https://github.com/etcd-io/etcd/releases/download/${version}/SHA256SUMS" -o "${manifest}"
+done
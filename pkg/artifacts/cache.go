@@ -0,0 +1,252 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/home"
+)
+
+const (
+	// GitHubTokenEnv is the environment variable of GitHub token, it raises the rate limit of GitHub API.
+	GitHubTokenEnv = "GITHUB_TOKEN"
+
+	// CacheTTLEnv is the environment variable that overrides the DefaultCacheTTL, for example, '30m'.
+	CacheTTLEnv = "GTCTL_CACHE_TTL"
+
+	// DefaultCacheTTL is the default time-to-live of the cached versions and chart index.
+	DefaultCacheTTL = time.Hour
+
+	// DefaultCacheDir is the default cache directory in the working directory of gtctl.
+	DefaultCacheDir = "cache"
+
+	versionsCacheFile  = "versions.json"
+	chartIndexCacheDir = "index"
+)
+
+// cachedVersion is the resolved version of a version specifier.
+type cachedVersion struct {
+	Version    string    `json:"version"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+// WithCacheDir sets the directory to cache the resolved versions and chart index, the empty dir disables the cache.
+func WithCacheDir(dir string) Option {
+	return func(m *manager) {
+		m.cacheDir = dir
+	}
+}
+
+// WithCacheTTL sets the time-to-live of the cached versions and chart index.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(m *manager) {
+		m.cacheTTL = ttl
+	}
+}
+
+// WithRefresh ignores the unexpired cache and always resolves the versions and downloads the chart index.
+// The cache is still updated and used as the fallback.
+func WithRefresh(refresh bool) Option {
+	return func(m *manager) {
+		m.refresh = refresh
+	}
+}
+
+// defaultWorkingDir returns the working directory in 'GTCTL_HOME' or the home directory of current user.
+func defaultWorkingDir() (string, error) {
+	return home.WorkingDir("")
+}
+
+// defaultCacheTTL returns the TTL from 'GTCTL_CACHE_TTL' or DefaultCacheTTL.
+func defaultCacheTTL() (time.Duration, error) {
+	value := os.Getenv(CacheTTLEnv)
+	if len(value) == 0 {
+		return DefaultCacheTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s': %v", CacheTTLEnv, value, err)
+	}
+	return ttl, nil
+}
+
+// cachedVersion returns the cached version of key. The expired version is also returned if ignoreTTL is true.
+func (m *manager) cachedVersion(key string, ignoreTTL bool) (string, bool) {
+	if len(m.cacheDir) == 0 {
+		return "", false
+	}
+
+	versions, err := m.loadVersionsCache()
+	if err != nil {
+		m.logger.V(3).Infof("Ignore the invalid versions cache: %v", err)
+		return "", false
+	}
+
+	cached, ok := versions[key]
+	if !ok || (!ignoreTTL && time.Since(cached.ResolvedAt) > m.cacheTTL) {
+		return "", false
+	}
+
+	return cached.Version, true
+}
+
+// cacheVersion caches the resolved version of key. The failure of caching is not fatal.
+func (m *manager) cacheVersion(key, version string) {
+	if len(m.cacheDir) == 0 {
+		return
+	}
+
+	if err := m.updateVersionsCache(key, version); err != nil {
+		m.logger.V(3).Infof("Failed to cache the version of '%s': %v", key, err)
+	}
+}
+
+// updateVersionsCache updates the version of key in the versions cache. The cache is read and written
+// under the file lock, so that the versions cached by the concurrent gtctl processes will not be lost.
+func (m *manager) updateVersionsCache(key, version string) error {
+	if err := fileutils.EnsureDir(m.cacheDir); err != nil {
+		return err
+	}
+
+	unlock, err := m.lockFile(context.TODO(), filepath.Join(m.cacheDir, versionsCacheLockFile), "updating the versions cache")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	versions, err := m.loadVersionsCache()
+	if err != nil {
+		versions = make(map[string]*cachedVersion)
+	}
+	versions[key] = &cachedVersion{Version: version, ResolvedAt: time.Now()}
+
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeCacheFile(filepath.Join(m.cacheDir, versionsCacheFile), data)
+}
+
+func (m *manager) loadVersionsCache() (map[string]*cachedVersion, error) {
+	versions := make(map[string]*cachedVersion)
+
+	data, err := os.ReadFile(filepath.Join(m.cacheDir, versionsCacheFile))
+	if os.IsNotExist(err) {
+		return versions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// versionCacheKey returns the cache key of the version specifier.
+// The mirrors are part of the key because they may publish different versions.
+func (m *manager) versionCacheKey(typ ArtifactType, name, version string, fromCNRegion bool) string {
+	mirrors, _ := json.Marshal(m.mirrors)
+	return fmt.Sprintf("%s/%s/%s/cn=%t/%s", typ, name, version, fromCNRegion, hashOf(string(mirrors)))
+}
+
+// chartIndexCacheFile returns the cache file of the chart index.
+func (m *manager) chartIndexCacheFile(indexURL string) string {
+	return filepath.Join(m.cacheDir, chartIndexCacheDir, hashOf(indexURL)+".yaml")
+}
+
+// cachedChartIndex returns the cached chart index. The expired index is also returned if ignoreTTL is true.
+func (m *manager) cachedChartIndex(indexURL string, ignoreTTL bool) ([]byte, bool) {
+	if len(m.cacheDir) == 0 {
+		return nil, false
+	}
+
+	cacheFile := m.chartIndexCacheFile(indexURL)
+	info, err := os.Stat(cacheFile)
+	if err != nil || (!ignoreTTL && time.Since(info.ModTime()) > m.cacheTTL) {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// cacheChartIndex caches the chart index. The failure of caching is not fatal.
+func (m *manager) cacheChartIndex(indexURL string, data []byte) {
+	if len(m.cacheDir) == 0 {
+		return
+	}
+
+	if err := writeCacheFile(m.chartIndexCacheFile(indexURL), data); err != nil {
+		m.logger.V(3).Infof("Failed to cache the chart index '%s': %v", indexURL, err)
+	}
+}
+
+// githubClient returns the GitHub client that authenticated by 'GITHUB_TOKEN' if it's set.
+func (m *manager) githubClient() *github.Client {
+	if token := os.Getenv(GitHubTokenEnv); len(token) > 0 {
+		return github.NewTokenClient(context.Background(), token)
+	}
+	return github.NewClient(nil)
+}
+
+// githubError adds the hint of GitHub token to the rate limit error.
+func githubError(err error) error {
+	var (
+		rateLimitErr      *github.RateLimitError
+		abuseRateLimitErr *github.AbuseRateLimitError
+	)
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseRateLimitErr) {
+		if len(os.Getenv(GitHubTokenEnv)) == 0 {
+			return fmt.Errorf("%v, set '%s' to raise the rate limit of GitHub API", err, GitHubTokenEnv)
+		}
+	}
+	return err
+}
+
+// writeCacheFile writes the cache file atomically, so that the concurrent gtctl will not read the partial file.
+func writeCacheFile(file string, data []byte) error {
+	if err := fileutils.EnsureDir(filepath.Dir(file)); err != nil {
+		return err
+	}
+	return fileutils.WriteFileAtomic(file, data, 0644)
+}
+
+func hashOf(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
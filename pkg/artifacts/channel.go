@@ -0,0 +1,267 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// NightlyVersionTag is the tag of the latest nightly version of greptime binary.
+	NightlyVersionTag = "nightly"
+
+	// StableVersionTag is the tag of the latest version that is not a pre-release.
+	StableVersionTag = "stable"
+
+	// versionListFile is the file that lists the released versions in the mirrors and the CN region, one version per line.
+	versionListFile = "versions.txt"
+)
+
+// resolveVersion resolves the version specifier to the specific version. The specifier can be:
+//   - the channels: 'latest', 'stable' and 'nightly';
+//   - the semantic version ranges, for example, '~0.4' or '>=0.4.0 <0.5';
+//   - the specific version, which is returned as it is.
+//
+// The resolved versions are cached, and the last resolved version is used if the resolution fails, e.g. rate limited by GitHub.
+func (m *manager) resolveVersion(typ ArtifactType, name, version string, fromCNRegion bool) (string, error) {
+	if !isVersionSpecifier(version) {
+		return version, nil
+	}
+
+	// Only the greptime binary has the nightly builds.
+	if version == NightlyVersionTag && (typ != ArtifactTypeBinary || name != GreptimeBinName) {
+		return "", fmt.Errorf("the '%s' version is only available for %s binary, not for %s '%s'", NightlyVersionTag, GreptimeBinName, typ, name)
+	}
+
+	key := m.versionCacheKey(typ, name, version, fromCNRegion)
+	if m.offline {
+		// The last resolved version is used offline even if it's expired.
+		if cached, ok := m.cachedVersion(key, true); ok {
+			return cached, nil
+		}
+		return "", fmt.Errorf("the version '%s' of %s '%s' has not been resolved before, it can't be resolved offline", version, typ, name)
+	}
+
+	if !m.refresh {
+		if cached, ok := m.cachedVersion(key, false); ok {
+			m.logger.V(3).Infof("Use the cached version '%s' of %s '%s' for '%s'", cached, typ, name, version)
+			return cached, nil
+		}
+	}
+
+	resolved, err := m.resolveVersionSpecifier(typ, name, version, fromCNRegion)
+	if err != nil {
+		cached, ok := m.cachedVersion(key, true)
+		if !ok {
+			return "", err
+		}
+		m.logger.Warnf("Failed to resolve the version '%s' of %s '%s': %v, use the last known version '%s'.", version, typ, name, err, cached)
+		return cached, nil
+	}
+	m.cacheVersion(key, resolved)
+
+	return resolved, nil
+}
+
+func (m *manager) resolveVersionSpecifier(typ ArtifactType, name, version string, fromCNRegion bool) (string, error) {
+	switch version {
+	case "", LatestVersionTag:
+		return m.resolveLatestVersion(typ, name, fromCNRegion)
+	case NightlyVersionTag:
+		return m.resolveNightlyVersion(typ, name, fromCNRegion)
+	case StableVersionTag:
+		return m.resolveVersionFromList(typ, name, version, fromCNRegion, func(v *semver.Version) bool {
+			return len(v.Prerelease()) == 0
+		})
+	}
+
+	constraint, _ := parseVersionRange(version)
+	return m.resolveVersionFromList(typ, name, version, fromCNRegion, constraint.Check)
+}
+
+// isVersionSpecifier returns true if the version is a channel or a semantic version range.
+func isVersionSpecifier(version string) bool {
+	switch version {
+	case "", LatestVersionTag, NightlyVersionTag, StableVersionTag:
+		return true
+	}
+
+	_, ok := parseVersionRange(version)
+	return ok
+}
+
+// resolveNightlyVersion resolves the latest nightly version of greptime binary.
+func (m *manager) resolveNightlyVersion(typ ArtifactType, name string, fromCNRegion bool) (string, error) {
+	if len(m.mirrors.GreptimeBinaries) > 0 {
+		return m.latestVersionFromURL(fmt.Sprintf("%s/latest-nightly-version.txt", strings.TrimSuffix(m.mirrors.GreptimeBinaries, "/")))
+	}
+
+	if fromCNRegion {
+		return m.getVersionInfoFromS3(typ, name, true)
+	}
+
+	return m.resolveVersionFromList(typ, name, NightlyVersionTag, false, func(v *semver.Version) bool {
+		return strings.Contains(v.Prerelease(), NightlyVersionTag)
+	})
+}
+
+// resolveVersionFromList resolves the newest released version that matches.
+func (m *manager) resolveVersionFromList(typ ArtifactType, name, specifier string, fromCNRegion bool, match func(*semver.Version) bool) (string, error) {
+	versions, err := m.listVersions(context.TODO(), typ, name, fromCNRegion)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		resolved string
+		newest   *semver.Version
+	)
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			// Skip the versions that are not semantic versions.
+			continue
+		}
+		if match(v) && (newest == nil || v.GreaterThan(newest)) {
+			resolved, newest = version, v
+		}
+	}
+
+	if newest == nil {
+		return "", fmt.Errorf("no version of %s '%s' matches '%s'", typ, name, specifier)
+	}
+
+	m.logger.V(3).Infof("Resolved the version '%s' of %s '%s' to '%s'", specifier, typ, name, resolved)
+
+	return resolved, nil
+}
+
+// listVersions lists the released versions to resolve the version specifiers. The versions are listed from
+// the mirror or the CN region if they are used, otherwise from the GitHub releases or the chart index.
+// The CN region falls back to GitHub if it doesn't list the versions.
+func (m *manager) listVersions(ctx context.Context, typ ArtifactType, name string, fromCNRegion bool) ([]string, error) {
+	listURL, fromMirror := m.versionListURL(typ, name)
+	if !fromMirror && fromCNRegion {
+		listURL = cnVersionListURL(typ, name)
+	}
+	if len(listURL) == 0 {
+		return m.ListVersions(ctx, name, typ)
+	}
+
+	versions, found, err := m.versionsFromURL(ctx, listURL)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return versions, nil
+	}
+	if fromMirror {
+		return nil, fmt.Errorf("the versions of %s '%s' are not listed in '%s', please use a specific version", typ, name, listURL)
+	}
+
+	m.logger.V(3).Infof("The versions of %s '%s' are not listed in '%s', list them from GitHub", typ, name, listURL)
+	return m.ListVersions(ctx, name, typ)
+}
+
+// versionListURL returns the URL of the version list in the mirror of artifact. It returns false if there is
+// no mirror for the artifact, or the mirror is the chart index that ListVersions reads already.
+func (m *manager) versionListURL(typ ArtifactType, name string) (string, bool) {
+	var base string
+	switch {
+	case typ == ArtifactTypeChart && len(m.mirrors.ChartIndex) > 0:
+		return "", false
+	case typ == ArtifactTypeChart && len(m.mirrors.Charts) > 0:
+		base = fmt.Sprintf("%s/%s", strings.TrimSuffix(m.mirrors.Charts, "/"), name)
+	case typ == ArtifactTypeBinary && name == GreptimeBinName && len(m.mirrors.GreptimeBinaries) > 0:
+		base = m.mirrors.GreptimeBinaries
+	case typ == ArtifactTypeBinary && name == EtcdBinName && len(m.mirrors.EtcdBinaries) > 0:
+		base = m.mirrors.EtcdBinaries
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(base, "/"), versionListFile), true
+}
+
+// cnVersionListURL returns the URL of the version list in the CN region.
+func cnVersionListURL(typ ArtifactType, name string) string {
+	switch {
+	case typ == ArtifactTypeChart:
+		return fmt.Sprintf("%s/%s/%s", GreptimeCNCharts, name, versionListFile)
+	case typ == ArtifactTypeBinary && name == GreptimeBinName:
+		return fmt.Sprintf("%s/%s", GreptimeDBCNBinaries, versionListFile)
+	case typ == ArtifactTypeBinary && name == EtcdBinName:
+		return fmt.Sprintf("%s/%s", EtcdCNBinaries, versionListFile)
+	default:
+		return ""
+	}
+}
+
+// versionsFromURL reads the versions from the version list file. It returns false if the file is not found.
+func (m *manager) versionsFromURL(ctx context.Context, listURL string) ([]string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("get versions from '%s' failed, status code: %d", listURL, resp.StatusCode)
+	}
+
+	var (
+		versions []string
+		scanner  = bufio.NewScanner(resp.Body)
+	)
+	for scanner.Scan() {
+		if version := strings.TrimSpace(scanner.Text()); len(version) > 0 {
+			versions = append(versions, version)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return versions, true, nil
+}
+
+// parseVersionRange parses the semantic version range. It returns false if the version is a specific version.
+func parseVersionRange(version string) (*semver.Constraints, bool) {
+	if _, err := semver.NewVersion(version); err == nil {
+		return nil, false
+	}
+
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return nil, false
+	}
+
+	return constraint, true
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestResolveVersion(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	index := `
+apiVersion: v1
+entries:
+  greptimedb-cluster:
+`
+	for _, version := range []string{"0.1.0", "0.1.1", "0.1.2-alpha.1", "0.2.0-alpha.1"} {
+		index += `  - apiVersion: v2
+    name: greptimedb-cluster
+    version: ` + version + `
+    urls:
+    - https://example.com/greptimedb-cluster-` + version + `.tgz
+`
+	}
+	indexFile := filepath.Join(tempDir, "index.yaml")
+	if err := os.WriteFile(indexFile, []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithMirrors(&Mirrors{ChartIndex: "file://" + indexFile}))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	tests := []struct {
+		version  string
+		expected string
+		wantErr  bool
+	}{
+		{version: LatestVersionTag, expected: "0.2.0-alpha.1"},
+		{version: StableVersionTag, expected: "0.1.1"},
+		{version: "~0.1", expected: "0.1.1"},
+		{version: ">=0.1.0 <0.1.1", expected: "0.1.0"},
+		{version: ">=0.1.2-0", expected: "0.2.0-alpha.1"},
+		{version: "0.1.0", expected: "0.1.0"},
+		{version: "0.3.0", expected: "0.3.0"},
+		{version: "~0.3", wantErr: true},
+		{version: NightlyVersionTag, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			src, err := m.NewSource(GreptimeDBClusterChartName, tt.version, ArtifactTypeChart, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for version '%s', got '%s'", tt.version, src.Version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to resolve version '%s': %v", tt.version, err)
+			}
+			if src.Version != tt.expected {
+				t.Errorf("expected version '%s', got '%s'", tt.expected, src.Version)
+			}
+		})
+	}
+}
+
+func TestResolveVersionWithCache(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	index := `
+apiVersion: v1
+entries:
+  greptimedb-cluster:
+  - apiVersion: v2
+    name: greptimedb-cluster
+    version: 0.1.1
+    urls:
+    - https://example.com/greptimedb-cluster-0.1.1.tgz
+`
+	var (
+		requests    int32
+		unavailable atomic.Bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if unavailable.Load() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(index))
+	}))
+	defer server.Close()
+
+	newManager := func(opts ...Option) Manager {
+		opts = append(opts, WithCacheDir(tempDir), WithMirrors(&Mirrors{ChartIndex: server.URL + "/index.yaml"}))
+		m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()), opts...)
+		if err != nil {
+			t.Fatalf("failed to create artifacts manager: %v", err)
+		}
+		return m
+	}
+
+	resolve := func(m Manager, version string) string {
+		src, err := m.NewSource(GreptimeDBClusterChartName, version, ArtifactTypeChart, false)
+		if err != nil {
+			t.Fatalf("failed to resolve version '%s': %v", version, err)
+		}
+		return src.Version
+	}
+
+	m := newManager()
+	if v := resolve(m, LatestVersionTag); v != "0.1.1" {
+		t.Errorf("expected version '0.1.1', got '%s'", v)
+	}
+
+	// The resolved version and the chart index are both cached.
+	resolve(m, LatestVersionTag)
+	resolve(m, "~0.1")
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request of chart index, got %d", n)
+	}
+
+	// The refresh ignores the cache and falls back to the last known version if the index is unavailable.
+	unavailable.Store(true)
+	if v := resolve(newManager(WithRefresh(true)), LatestVersionTag); v != "0.1.1" {
+		t.Errorf("expected the last known version '0.1.1', got '%s'", v)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests of chart index, got %d", n)
+	}
+
+	// Without the cache, the resolution fails.
+	m, err = NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithCacheDir(""), WithMirrors(&Mirrors{ChartIndex: server.URL + "/index.yaml"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.NewSource(GreptimeDBClusterChartName, LatestVersionTag, ArtifactTypeChart, false); err == nil {
+		t.Errorf("expected error when the chart index is unavailable")
+	}
+}
+
+func TestResolveVersionFromMirrorList(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The mirrors of binaries list the versions in 'versions.txt'.
+	greptimeDir := filepath.Join(tempDir, "greptimedb")
+	if err := os.MkdirAll(greptimeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	versions := "v0.5.0-nightly-20231101\nv0.4.2\nv0.4.1\nv0.3.2\n"
+	if err := os.WriteFile(filepath.Join(greptimeDir, versionListFile), []byte(versions), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()), WithCacheDir(""),
+		WithMirrors(&Mirrors{
+			GreptimeBinaries: "file://" + greptimeDir,
+			EtcdBinaries:     "file://" + filepath.Join(tempDir, "etcd"),
+		}))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		expected string
+		wantErr  bool
+	}{
+		{name: GreptimeBinName, version: StableVersionTag, expected: "v0.4.2"},
+		{name: GreptimeBinName, version: "~0.3", expected: "v0.3.2"},
+		{name: GreptimeBinName, version: "~0.6", wantErr: true},
+
+		// The mirror that doesn't list the versions should not fall back to GitHub.
+		{name: EtcdBinName, version: StableVersionTag, wantErr: true},
+
+		// Only the greptime binary has the nightly builds.
+		{name: EtcdBinName, version: NightlyVersionTag, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.version, func(t *testing.T) {
+			version, err := m.(*manager).resolveVersion(ArtifactTypeBinary, tt.name, tt.version, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for version '%s', got '%s'", tt.version, version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to resolve version '%s': %v", tt.version, err)
+			}
+			if version != tt.expected {
+				t.Errorf("expected version '%s', got '%s'", tt.expected, version)
+			}
+		})
+	}
+}
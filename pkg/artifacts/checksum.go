@@ -0,0 +1,236 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	// ChecksumFileSuffix is the suffix of the file that records the checksum of the downloaded artifact.
+	// The file is written only after the artifact is downloaded and verified completely,
+	// so the cached artifact without it will be regarded as incomplete.
+	ChecksumFileSuffix = ".sha256"
+
+	// etcdChecksumManifest is the checksum manifest of all the files in an etcd release.
+	etcdChecksumManifest = "SHA256SUMS"
+
+	// unverifiedChecksumMark follows the checksum in the checksum file if the artifact is not verified
+	// because its published checksum is missing. The cached artifact with it will be verified again.
+	unverifiedChecksumMark = "unverified"
+
+	// embeddedChecksumManifest is the path of the embedded checksum manifest of '<name>-<version>'.
+	embeddedChecksumManifest = "checksums/%s-%s.SHA256SUMS"
+)
+
+var (
+	//go:embed checksums
+	embeddedChecksumManifests embed.FS
+
+	// checksumManifests are the checksum manifests of the pinned versions, which are embedded in gtctl,
+	// so that the artifacts of these versions can be verified without the published checksums.
+	checksumManifests fs.FS = embeddedChecksumManifests
+
+	// errChecksumNotFound means the checksum should be published but it's not found.
+	errChecksumNotFound = errors.New("checksum not found")
+)
+
+// verifyArtifact verifies the downloaded artifact with the checksum of source and records its checksum.
+// If the source has no published checksum, only the checksum of the downloaded file will be recorded.
+// If the published checksum is missing, the artifact is recorded as unverified.
+func (m *manager) verifyArtifact(ctx context.Context, src *Source, artifactFile string) error {
+	actual, err := FileSHA256(artifactFile)
+	if err != nil {
+		return err
+	}
+
+	expected, err := m.expectedChecksum(ctx, src)
+	if err != nil && !errors.Is(err, errChecksumNotFound) {
+		return err
+	}
+
+	record := actual
+	switch {
+	case len(expected) > 0:
+		if !strings.EqualFold(expected, actual) {
+			// Remove the corrupted artifact so that it will not be used as cache.
+			if err := os.Remove(artifactFile); err != nil {
+				m.logger.V(3).Infof("failed to remove corrupted artifact '%s': %v", artifactFile, err)
+			}
+			return fmt.Errorf("checksum mismatch of '%s', expected '%s', got '%s'", src.FileName, expected, actual)
+		}
+		m.logger.V(3).Infof("The checksum of artifact '%s' is verified", artifactFile)
+	case err != nil:
+		m.logger.Warnf("WARNING: The checksum of '%s' is not found in '%s', the artifact is NOT verified!", src.FileName, src.ChecksumURL)
+		record = fmt.Sprintf("%s %s", actual, unverifiedChecksumMark)
+	default:
+		m.logger.V(3).Infof("No checksum is published for '%s', skip verifying", src.FileName)
+	}
+
+	return os.WriteFile(artifactFile+ChecksumFileSuffix, []byte(record+"\n"), 0644)
+}
+
+// isCacheValid returns true if the cached artifact is complete and not modified since it's downloaded.
+// It also returns whether the cached artifact is verified with the published checksum.
+func (m *manager) isCacheValid(artifactFile string) (bool, bool, error) {
+	if _, err := os.Stat(artifactFile); err != nil {
+		if os.IsNotExist(err) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	data, err := os.ReadFile(artifactFile + ChecksumFileSuffix)
+	if os.IsNotExist(err) {
+		m.logger.V(3).Infof("The cached artifact '%s' is incomplete, download it again.", artifactFile)
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	actual, err := FileSHA256(artifactFile)
+	if err != nil {
+		return false, false, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || !strings.EqualFold(fields[0], actual) {
+		m.logger.V(3).Infof("The cached artifact '%s' is corrupted, download it again.", artifactFile)
+		return false, false, nil
+	}
+
+	return true, len(fields) < 2 || fields[1] != unverifiedChecksumMark, nil
+}
+
+// expectedChecksum returns the checksum of the source, or empty string if it's not published.
+// The embedded checksum manifest of the pinned version takes precedence over the published one.
+// It returns errChecksumNotFound if the checksum should be published but it's not found.
+func (m *manager) expectedChecksum(ctx context.Context, src *Source) (string, error) {
+	if checksum, ok := embeddedChecksum(src); ok {
+		m.logger.V(3).Infof("Use the embedded checksum of '%s'", src.FileName)
+		return checksum, nil
+	}
+
+	if len(src.ChecksumURL) == 0 {
+		return "", nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.ChecksumURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errChecksumNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get checksum from '%s' failed, status code: %d", src.ChecksumURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return parseChecksum(data, src.FileName)
+}
+
+// embeddedChecksum returns the checksum of the source in the embedded checksum manifest of its version.
+func embeddedChecksum(src *Source) (string, bool) {
+	if src.Type != ArtifactTypeBinary {
+		return "", false
+	}
+
+	data, err := fs.ReadFile(checksumManifests, fmt.Sprintf(embeddedChecksumManifest, src.Name, src.Version))
+	if err != nil {
+		return "", false
+	}
+
+	checksum, err := parseChecksum(data, src.FileName)
+	if err != nil {
+		return "", false
+	}
+
+	return checksum, true
+}
+
+// parseChecksum parses the checksum of fileName from the output of 'sha256sum'.
+// The data can be the checksum of single file or the checksums of multiple files.
+func parseChecksum(data []byte, fileName string) (string, error) {
+	var (
+		lines   [][]string
+		scanner = bufio.NewScanner(bytes.NewReader(data))
+	)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			lines = append(lines, fields)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	for _, fields := range lines {
+		// The checksum file of single file may only have the checksum.
+		if len(fields) == 1 && len(lines) == 1 {
+			return fields[0], nil
+		}
+
+		// The format is '<checksum>  <file>' or '<checksum> *<file>' in binary mode.
+		if len(fields) == 2 && path.Base(strings.TrimPrefix(fields[1], "*")) == fileName {
+			return fields[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("checksum of '%s' is not found", fileName)
+}
+
+// FileSHA256 returns the hex encoded sha256 checksum of the file, which is the same as the output of 'sha256sum'.
+func FileSHA256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestParseChecksum(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		fileName string
+		want     string
+		err      bool
+	}{
+		{"single", "abc  greptime-linux-amd64-v0.4.0.tar.gz\n", "greptime-linux-amd64-v0.4.0.tar.gz", "abc", false},
+		{"only-checksum", "abc\n", "greptime-linux-amd64-v0.4.0.tar.gz", "abc", false},
+		{"manifest", "abc  etcd-v3.5.7-darwin-amd64.zip\ndef  etcd-v3.5.7-linux-amd64.tar.gz\n", "etcd-v3.5.7-linux-amd64.tar.gz", "def", false},
+		{"binary-mode", "abc *./etcd-v3.5.7-linux-amd64.tar.gz\n", "etcd-v3.5.7-linux-amd64.tar.gz", "abc", false},
+		{"not-found", "abc  etcd-v3.5.7-darwin-amd64.zip\ndef  etcd-v3.5.7-windows-amd64.zip\n", "etcd-v3.5.7-linux-amd64.tar.gz", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseChecksum([]byte(tt.data), tt.fileName)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDownloadWithChecksum(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var (
+		content   = []byte("greptimedb-cluster chart")
+		checksum  = sha256.Sum256(content)
+		downloads int
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chart.tgz", func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		_, _ = w.Write(content)
+	})
+	mux.HandleFunc("/chart.tgz.sha256sum", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(hex.EncodeToString(checksum[:]) + "  chart.tgz\n"))
+	})
+	mux.HandleFunc("/bad.sha256sum", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0000  chart.tgz\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	ctx := context.Background()
+	src := &Source{
+		Name:        GreptimeDBClusterChartName,
+		FileName:    "chart.tgz",
+		URL:         server.URL + "/chart.tgz",
+		ChecksumURL: server.URL + "/chart.tgz.sha256sum",
+		Version:     "0.1.2",
+		Type:        ArtifactTypeChart,
+	}
+
+	artifactFile, err := m.DownloadTo(ctx, src, tempDir, &DownloadOptions{EnableCache: true})
+	if err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if _, err = os.Stat(artifactFile + ChecksumFileSuffix); err != nil {
+		t.Errorf("checksum file should be written: %v", err)
+	}
+
+	// The valid cache should be reused.
+	if _, err = m.DownloadTo(ctx, src, tempDir, &DownloadOptions{EnableCache: true}); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if downloads != 1 {
+		t.Errorf("expected 1 download, got %d", downloads)
+	}
+
+	// The corrupted cache should be downloaded again.
+	if err = os.WriteFile(artifactFile, []byte("truncated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.DownloadTo(ctx, src, tempDir, &DownloadOptions{EnableCache: true}); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if downloads != 2 {
+		t.Errorf("expected 2 downloads, got %d", downloads)
+	}
+
+	// The artifact that mismatches the checksum should be rejected and removed.
+	src.ChecksumURL = server.URL + "/bad.sha256sum"
+	badDir := filepath.Join(tempDir, "bad")
+	if _, err = m.DownloadTo(ctx, src, badDir, &DownloadOptions{EnableCache: true}); err == nil {
+		t.Fatalf("expected checksum mismatch error")
+	}
+	if _, err = os.Stat(filepath.Join(badDir, src.FileName)); !os.IsNotExist(err) {
+		t.Errorf("corrupted artifact should be removed")
+	}
+}
+
+func TestDownloadWithMissingChecksum(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var (
+		content   = []byte("greptimedb-cluster chart")
+		checksum  = sha256.Sum256(content)
+		published bool
+		downloads int
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chart.tgz", func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		_, _ = w.Write(content)
+	})
+	mux.HandleFunc("/chart.tgz.sha256sum", func(w http.ResponseWriter, r *http.Request) {
+		if !published {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(hex.EncodeToString(checksum[:]) + "  chart.tgz\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	ctx := context.Background()
+	src := &Source{
+		Name:        GreptimeDBClusterChartName,
+		FileName:    "chart.tgz",
+		URL:         server.URL + "/chart.tgz",
+		ChecksumURL: server.URL + "/chart.tgz.sha256sum",
+		Version:     "0.1.2",
+		Type:        ArtifactTypeChart,
+	}
+
+	// The artifact whose checksum is missing is downloaded but recorded as unverified.
+	artifactFile, err := m.DownloadTo(ctx, src, tempDir, &DownloadOptions{EnableCache: true})
+	if err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	data, err := os.ReadFile(artifactFile + ChecksumFileSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), unverifiedChecksumMark) {
+		t.Errorf("expected the artifact is recorded as unverified, got '%s'", string(data))
+	}
+
+	// The cached artifact is verified again once the checksum is published.
+	published = true
+	if _, err = m.DownloadTo(ctx, src, tempDir, &DownloadOptions{EnableCache: true}); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	if downloads != 1 {
+		t.Errorf("expected 1 download, got %d", downloads)
+	}
+	data, err = os.ReadFile(artifactFile + ChecksumFileSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != hex.EncodeToString(checksum[:]) {
+		t.Errorf("expected the artifact is recorded as verified, got '%s'", string(data))
+	}
+}
+
+func TestExpectedChecksumFromEmbeddedManifest(t *testing.T) {
+	defer func(manifests fs.FS) { checksumManifests = manifests }(checksumManifests)
+	checksumManifests = fstest.MapFS{
+		"checksums/etcd-v3.5.7.SHA256SUMS": &fstest.MapFile{
+			Data: []byte("abc  etcd-v3.5.7-darwin-amd64.zip\ndef  etcd-v3.5.7-linux-amd64.tar.gz\n"),
+		},
+	}
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	// The embedded checksum is used without requesting the published one.
+	src := &Source{
+		Name:        EtcdBinName,
+		FileName:    "etcd-v3.5.7-linux-amd64.tar.gz",
+		ChecksumURL: "http://127.0.0.1:0/SHA256SUMS",
+		Version:     "v3.5.7",
+		Type:        ArtifactTypeBinary,
+	}
+	checksum, err := m.(*manager).expectedChecksum(context.Background(), src)
+	if err != nil {
+		t.Fatalf("failed to get the expected checksum: %v", err)
+	}
+	if checksum != "def" {
+		t.Errorf("expected checksum 'def', got '%s'", checksum)
+	}
+
+	// The version without embedded manifest uses the published checksum.
+	src.Version, src.FileName, src.ChecksumURL = "v3.5.8", "etcd-v3.5.8-linux-amd64.tar.gz", ""
+	if checksum, err = m.(*manager).expectedChecksum(context.Background(), src); err != nil || checksum != "" {
+		t.Errorf("expected no checksum, got '%s', %v", checksum, err)
+	}
+}
+
+func TestEmbeddedChecksumOfDefaultEtcd(t *testing.T) {
+	// The real embedded manifests are used.
+	manifest := fmt.Sprintf(embeddedChecksumManifest, EtcdBinName, DefaultEtcdBinVersion)
+	data, err := fs.ReadFile(embeddedChecksumManifests, manifest)
+	if err != nil {
+		t.Skipf("the checksum manifest '%s' of the pinned etcd is not embedded, generate it by 'make update-checksums': %v", manifest, err)
+	}
+
+	for _, platform := range []Platform{{OS: "linux", Arch: "amd64"}, {OS: "linux", Arch: "arm64"}, {OS: "darwin", Arch: "arm64"}} {
+		pkg, _, err := packageNames(EtcdBinName, DefaultEtcdBinVersion, platform)
+		if err != nil {
+			t.Fatalf("failed to get the package name of etcd on %v: %v", platform, err)
+		}
+
+		// The digest is the one in the manifest that generated from the release of etcd.
+		expected, err := parseChecksum(data, pkg)
+		if err != nil {
+			t.Errorf("no checksum of '%s' in '%s': %v", pkg, manifest, err)
+			continue
+		}
+		checksum, ok := embeddedChecksum(&Source{Name: EtcdBinName, Version: DefaultEtcdBinVersion, FileName: pkg, Type: ArtifactTypeBinary})
+		if !ok || checksum != expected {
+			t.Errorf("expected the embedded checksum '%s' of '%s', got '%s'", expected, pkg, checksum)
+		}
+		if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+			t.Errorf("invalid SHA256 digest '%s' of '%s'", checksum, pkg)
+		}
+	}
+}
@@ -73,4 +73,7 @@ const (
 
 	// DefaultEtcdBinVersion is the default etcd binary version.
 	DefaultEtcdBinVersion = "v3.5.7"
+
+	// maxListedReleases is the max number of GitHub releases to list.
+	maxListedReleases = 100
 )
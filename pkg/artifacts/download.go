@@ -0,0 +1,183 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+)
+
+const (
+	// partialFileSuffix is the suffix of the file that is being downloaded.
+	// The partial file will be resumed by the next download and renamed to the artifact file after it's completed.
+	partialFileSuffix = ".part"
+
+	// downloadRetries is the max retries of downloading after the first attempt.
+	downloadRetries = 3
+
+	// defaultDownloadRetryInterval is the interval before the first retry.
+	defaultDownloadRetryInterval = time.Second
+)
+
+// permanentError is the error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// downloadFromHTTP downloads the file from httpURL to dest. The file is streamed to a partial file and
+// renamed to dest atomically after it's completed. The partial file will be resumed by HTTP Range request
+// if the download is interrupted, and the download will be retried with exponential backoff.
+func (m *manager) downloadFromHTTP(ctx context.Context, httpURL string, dest string, spinner *status.Spinner) error {
+	var (
+		partialFile = dest + partialFileSuffix
+		progress    = status.NewProgress(spinner, fmt.Sprintf("Downloading %s", path.Base(dest)))
+		backoff     = m.retryInterval
+		err         error
+	)
+	defer progress.Done()
+
+	for attempt := 0; attempt <= downloadRetries; attempt++ {
+		if attempt > 0 {
+			m.logger.V(3).Infof("Retry downloading '%s' in %s: %v", httpURL, backoff, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		if err = m.downloadPartial(ctx, httpURL, partialFile, progress); err == nil {
+			return os.Rename(partialFile, dest)
+		}
+
+		var pe *permanentError
+		if errors.As(err, &pe) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return err
+}
+
+// downloadPartial downloads the remaining part of the partial file.
+func (m *manager) downloadPartial(ctx context.Context, httpURL string, partialFile string, progress *status.Progress) error {
+	var offset int64
+	if info, err := os.Stat(partialFile); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return &permanentError{err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL, nil)
+	if err != nil {
+		return &permanentError{err}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		m.logger.V(3).Infof("Resume downloading '%s' from %d bytes", httpURL, offset)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	total := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+		if total, err = contentRangeTotal(resp.Header.Get("Content-Range")); err != nil {
+			return err
+		}
+	case http.StatusOK:
+		// The server does not support range request or there is no partial file, download from the beginning.
+		flags |= os.O_TRUNC
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is invalid, remove it and download again.
+		if err := os.Remove(partialFile); err != nil {
+			return &permanentError{err}
+		}
+		return fmt.Errorf("invalid partial file of '%s'", httpURL)
+	default:
+		err := fmt.Errorf("download failed, status code: %d", resp.StatusCode)
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests &&
+			resp.StatusCode != http.StatusRequestTimeout {
+			return &permanentError{err}
+		}
+		return err
+	}
+
+	file, err := os.OpenFile(partialFile, flags, 0644)
+	if err != nil {
+		return &permanentError{err}
+	}
+	defer file.Close()
+
+	progress.Reset(offset, total)
+	written, err := io.Copy(file, io.TeeReader(resp.Body, progress))
+	if err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return &permanentError{err}
+	}
+
+	// The response may be truncated if the connection is closed unexpectedly.
+	if total >= 0 && offset+written != total {
+		return fmt.Errorf("download incomplete, expected %d bytes, got %d bytes", total, offset+written)
+	}
+
+	return nil
+}
+
+// contentRangeTotal returns the total size in the header like 'bytes 100-199/200', or -1 if the total is unknown.
+func contentRangeTotal(contentRange string) (int64, error) {
+	idx := strings.LastIndex(contentRange, "/")
+	if !strings.HasPrefix(contentRange, "bytes ") || idx < 0 {
+		return 0, &permanentError{fmt.Errorf("invalid Content-Range '%s'", contentRange)}
+	}
+
+	total := contentRange[idx+1:]
+	if total == "*" {
+		return -1, nil
+	}
+
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, &permanentError{fmt.Errorf("invalid Content-Range '%s'", contentRange)}
+	}
+
+	return size, nil
+}
@@ -0,0 +1,427 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestDownloadResume(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	content := bytes.Repeat([]byte("greptime"), 1024)
+
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "greptime.tar.gz", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	m := newTestManager(t)
+
+	// The partial file that is interrupted in the last download.
+	dest := filepath.Join(tempDir, "greptime.tar.gz")
+	if err := os.WriteFile(dest+partialFileSuffix, content[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.downloadFromHTTP(context.Background(), server.URL, dest, nil); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, data) {
+		t.Errorf("the downloaded content is not the same as the original")
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=1000-" {
+		t.Errorf("expected to resume from 1000 bytes, got ranges %v", ranges)
+	}
+	if _, err = os.Stat(dest + partialFileSuffix); !os.IsNotExist(err) {
+		t.Errorf("the partial file should be renamed")
+	}
+}
+
+func TestDownloadRetry(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	content := bytes.Repeat([]byte("greptime"), 1024)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			// Close the connection after sending half of the content.
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content[:len(content)/2])
+		default:
+			http.ServeContent(w, r, "greptime.tar.gz", time.Time{}, bytes.NewReader(content))
+		}
+	}))
+	defer server.Close()
+
+	m := newTestManager(t)
+
+	dest := filepath.Join(tempDir, "greptime.tar.gz")
+	if err := m.downloadFromHTTP(context.Background(), server.URL, dest, nil); err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, data) {
+		t.Errorf("the downloaded content is not the same as the original")
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	// The client error should not be retried.
+	requests = 0
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer notFound.Close()
+
+	if err := m.downloadFromHTTP(context.Background(), notFound.URL, filepath.Join(tempDir, "not-found"), nil); err == nil {
+		t.Errorf("expected error for not found")
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestConcurrentDownloadAndInstall(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The package that contains an executable file.
+	pkg := newTestPackage(t, "greptime")
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Ext(r.URL.Path) != ".gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		http.ServeContent(w, r, "greptime.tar.gz", time.Time{}, bytes.NewReader(pkg))
+	}))
+	defer server.Close()
+
+	m := newTestManager(t)
+	src := &Source{
+		Name:     GreptimeBinName,
+		Type:     ArtifactTypeBinary,
+		Version:  "v0.4.0",
+		FileName: "greptime.tar.gz",
+		URL:      server.URL + "/greptime.tar.gz",
+	}
+	destDir := filepath.Join(tempDir, "v0.4.0", "pkg")
+	installDir := filepath.Join(tempDir, "v0.4.0", "bin")
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 4)
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			binPath, err := m.DownloadTo(context.Background(), src, destDir, &DownloadOptions{EnableCache: true, BinaryInstallDir: installDir})
+			if err == nil {
+				_, err = os.Stat(binPath)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("failed to download and install concurrently: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected the package to be downloaded once, got %d", n)
+	}
+	if _, err := os.Stat(filepath.Join(installDir, installedMarkerFile)); err != nil {
+		t.Errorf("the installed marker is not written: %v", err)
+	}
+
+	// The staging directories are cleaned up.
+	entries, err := os.ReadDir(filepath.Join(tempDir, "v0.4.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected only 'pkg' and 'bin' directories, got %v", entries)
+	}
+}
+
+func TestInstallExpectedBinaries(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	m := newTestManager(t)
+
+	// Only the expected binaries are installed.
+	pkgFile := filepath.Join(tempDir, "etcd.tar.gz")
+	if err := os.WriteFile(pkgFile, newTestPackage(t, "etcd-v3.5.7/etcd", "etcd-v3.5.7/etcdctl", "etcd-v3.5.7/etcdutl"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pkgFile+ChecksumFileSuffix, []byte("checksum\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installDir := filepath.Join(tempDir, "bin")
+	if err := m.installBinaries(pkgFile, installDir, []string{"etcd", "etcdctl"}); err != nil {
+		t.Fatalf("failed to install binaries: %v", err)
+	}
+	for _, binary := range []string{"etcd", "etcdctl"} {
+		if _, err := os.Stat(filepath.Join(installDir, binary)); err != nil {
+			t.Errorf("binary '%s' is not installed: %v", binary, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(installDir, "etcdutl")); !os.IsNotExist(err) {
+		t.Errorf("the unexpected binary 'etcdutl' is installed")
+	}
+
+	// The package that lacks the expected binary is rejected.
+	if err := m.installBinaries(pkgFile, filepath.Join(tempDir, "other"), []string{"greptime"}); err == nil {
+		t.Errorf("expected error for the missing binary")
+	}
+
+	// The binaries installed from another package replace the old ones, and nothing is left aside.
+	if err := os.WriteFile(pkgFile+ChecksumFileSuffix, []byte("another checksum\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.installBinaries(pkgFile, installDir, []string{"etcd"}); err != nil {
+		t.Fatalf("failed to reinstall binaries: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(installDir, "etcdctl")); !os.IsNotExist(err) {
+		t.Errorf("the old binary 'etcdctl' is not replaced")
+	}
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".staging-") {
+			t.Errorf("the staging directory '%s' is left", entry.Name())
+		}
+	}
+}
+
+func TestInstallGzippedBinary(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	m := newTestManager(t)
+
+	// The gzipped binary is named differently from the binary.
+	pkgFile := filepath.Join(tempDir, "greptime-linux-amd64.gz")
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte("#!/bin/sh\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pkgFile, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pkgFile+ChecksumFileSuffix, []byte("checksum\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installDir := filepath.Join(tempDir, "bin")
+	if err := m.installBinaries(pkgFile, installDir, []string{GreptimeBinName}); err != nil {
+		t.Fatalf("failed to install the gzipped binary: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(installDir, GreptimeBinName))
+	if err != nil {
+		t.Fatalf("binary '%s' is not installed: %v", GreptimeBinName, err)
+	}
+	if string(data) != "#!/bin/sh\n" {
+		t.Errorf("unexpected content of the installed binary: %s", string(data))
+	}
+
+	// The gzipped binary can't provide multiple binaries.
+	if err := m.installBinaries(pkgFile, filepath.Join(tempDir, "other"), []string{"etcd", "etcdctl"}); err == nil {
+		t.Errorf("expected error for multiple binaries in the gzipped binary")
+	}
+}
+
+func TestConcurrentCacheVersions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	m := newTestManager(t)
+	m.cacheDir = tempDir
+
+	// The versions cached concurrently should not overwrite each other.
+	const keys = 20
+	var wg sync.WaitGroup
+	for i := 0; i < keys; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.cacheVersion("key-"+strconv.Itoa(i), "v0.1."+strconv.Itoa(i))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < keys; i++ {
+		version, ok := m.cachedVersion("key-"+strconv.Itoa(i), false)
+		if !ok || version != "v0.1."+strconv.Itoa(i) {
+			t.Errorf("expected cached version 'v0.1.%d' of 'key-%d', got '%s'", i, i, version)
+		}
+	}
+}
+
+// newTestPackage creates the tar.gz package that contains the executable files.
+func newTestPackage(t *testing.T, names ...string) []byte {
+	var pkg bytes.Buffer
+	gw := gzip.NewWriter(&pkg)
+	tw := tar.NewWriter(gw)
+	content := []byte("#!/bin/sh\n")
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return pkg.Bytes()
+}
+
+func newTestManager(t *testing.T) *manager {
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	am := m.(*manager)
+	am.retryInterval = 10 * time.Millisecond
+	return am
+}
+
+func TestOfflineDownload(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	m := newTestManager(t)
+	m.cacheDir = tempDir
+	m.offline = true
+
+	// The version specifier that has not been resolved can't be resolved offline.
+	if _, err := m.NewSource(GreptimeDBClusterChartName, LatestVersionTag, ArtifactTypeChart, false); err == nil {
+		t.Errorf("expected error for resolving the version offline")
+	}
+
+	// The last resolved version is used offline.
+	m.cacheVersion(m.versionCacheKey(ArtifactTypeChart, GreptimeDBClusterChartName, LatestVersionTag, false), "0.1.2")
+	src, err := m.NewSource(GreptimeDBClusterChartName, LatestVersionTag, ArtifactTypeChart, false)
+	if err != nil {
+		t.Fatalf("failed to resolve the cached version offline: %v", err)
+	}
+	if src.Version != "0.1.2" {
+		t.Errorf("expected version '0.1.2', got '%s'", src.Version)
+	}
+
+	// The artifact that is not cached can't be downloaded offline.
+	destDir := filepath.Join(tempDir, "chart")
+	if _, err := m.DownloadTo(context.Background(), src, destDir, &DownloadOptions{}); err == nil {
+		t.Errorf("expected error for downloading offline")
+	}
+
+	// The cached artifact is used offline.
+	artifactFile := filepath.Join(destDir, src.FileName)
+	if err := os.WriteFile(artifactFile, []byte("chart"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	checksum, err := FileSHA256(artifactFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(artifactFile+ChecksumFileSuffix, []byte(checksum+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.offline = true
+	if _, err := m.DownloadTo(context.Background(), src, destDir, &DownloadOptions{}); err != nil {
+		t.Errorf("failed to use the cached artifact offline: %v", err)
+	}
+}
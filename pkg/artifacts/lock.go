@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// lockFileName is the lock file in the download directory of artifact.
+	lockFileName = ".lock"
+
+	// versionsCacheLockFile is the lock file in the cache directory that serializes the updates of versions cache.
+	versionsCacheLockFile = ".versions.lock"
+
+	// installedMarkerFile is written in the install directory of binaries after they are installed completely.
+	// It records the checksum of the package that the binaries are installed from.
+	installedMarkerFile = ".installed"
+
+	// lockRetryInterval is the interval to retry acquiring the lock that held by another process.
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+// lockDir acquires the exclusive file lock of dir, so that the gtctl processes that download and install
+// the same artifact are serialized. It blocks until the lock is acquired or the ctx is done.
+// The lock is released automatically if the process exits.
+func (m *manager) lockDir(ctx context.Context, dir string) (func(), error) {
+	return m.lockFile(ctx, filepath.Join(dir, lockFileName), fmt.Sprintf("downloading to '%s'", dir))
+}
+
+// lockFile acquires the exclusive file lock of lockFile. The activity describes what the lock holder
+// is doing, which is shown while waiting for it.
+func (m *manager) lockFile(ctx context.Context, lockFile, activity string) (func(), error) {
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	waiting := false
+	for {
+		err := fileutils.TryLockFile(f)
+		if err == nil {
+			break
+		}
+		if err != fileutils.ErrLocked {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock '%s': %v", lockFile, err)
+		}
+
+		if !waiting {
+			m.logger.V(0).Infof("Waiting for another gtctl process that is %s...", activity)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	return func() {
+		if err := fileutils.UnlockFile(f); err != nil {
+			m.logger.V(3).Infof("failed to unlock '%s': %v", lockFile, err)
+		}
+		f.Close()
+	}, nil
+}
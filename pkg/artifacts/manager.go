@@ -17,15 +17,16 @@
 package artifacts
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v53/github"
 	"helm.sh/helm/v3/pkg/action"
@@ -36,18 +37,22 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
-	semverutils "github.com/GreptimeTeam/gtctl/pkg/utils/semver"
 )
 
 // Manager is the interface for managing artifacts.
 // For now, the artifacts can be helm charts and binaries.
 type Manager interface {
 	// NewSource creates an artifact source with name, version, type and fromCNRegion.
-	NewSource(name, version string, typ ArtifactType, fromCNRegion bool) (*Source, error)
+	// The version of binary can also be the OCI reference, e.g. 'oci://registry.example.com/greptime:v0.4.0'.
+	NewSource(name, version string, typ ArtifactType, fromCNRegion bool, opts ...SourceOption) (*Source, error)
 
 	// DownloadTo downloads the artifact from the source to the dest and returns the path of the artifact.
 	DownloadTo(ctx context.Context, from *Source, destDir string, opts *DownloadOptions) (string, error)
+
+	// ListVersions lists the released versions of the artifact, the newer versions come first.
+	ListVersions(ctx context.Context, name string, typ ArtifactType) ([]string, error)
 }
 
 // ArtifactType is the type of the artifact.
@@ -72,6 +77,10 @@ type Source struct {
 	// The URL of the artifact. It can be the normal http/https URL or the OCI URL.
 	URL string
 
+	// The ChecksumURL is the URL of the sha256 checksum of the artifact. It can be the checksum file of the artifact
+	// or the checksum manifest of all the files in a release. It's empty if the checksum is not published.
+	ChecksumURL string
+
 	// The Version of the artifact.
 	Version string
 
@@ -80,6 +89,42 @@ type Source struct {
 
 	// Indicates whether the artifact is from the CN region.
 	FromCNRegion bool
+
+	// The target Platform of binary, the default is the platform of the manager.
+	Platform Platform
+
+	// The Binaries are the names of executable files to install from the package of binary.
+	// The default is the known binaries of greptime and etcd, or the Name of the artifact.
+	Binaries []string
+}
+
+// SourceOption is the option to create the source.
+type SourceOption func(*Source)
+
+// WithSourcePlatform sets the target platform of binary, so that the binaries of other platforms can be prepared.
+func WithSourcePlatform(platform Platform) SourceOption {
+	return func(s *Source) {
+		s.Platform = platform
+	}
+}
+
+// knownBinaries are the binaries to install from the packages of artifacts.
+var knownBinaries = map[string][]string{
+	GreptimeBinName: {"greptime"},
+
+	// The 'etcdctl' is used to check the healthy of etcd.
+	EtcdBinName: {"etcd", "etcdctl"},
+}
+
+// binaries returns the binaries to install from the package of the source.
+func (s *Source) binaries() []string {
+	if len(s.Binaries) > 0 {
+		return s.Binaries
+	}
+	if binaries, ok := knownBinaries[s.Name]; ok {
+		return binaries
+	}
+	return []string{s.Name}
 }
 
 // DownloadOptions is the options for downloading the artifact.
@@ -89,181 +134,290 @@ type DownloadOptions struct {
 
 	// If the artifact is a binary, the manager will install the binary to the BinaryInstallDir after downloading its package.
 	BinaryInstallDir string
+
+	// If SkipInstall is true, the package of binary will only be downloaded without installing.
+	SkipInstall bool
+
+	// Spinner is used to show the progress of downloading, it can be nil.
+	Spinner *status.Spinner
 }
 
 // manager is the implementation of Manager interface.
 type manager struct {
 	logger logger.Logger
+
+	// retryInterval is the interval before the first retry of downloading, it doubles after each retry.
+	retryInterval time.Duration
+
+	// mirrors replaces the default download locations of artifacts.
+	mirrors *Mirrors
+
+	// httpClient is the client for downloading, it supports 'http', 'https' and 'file' schemes.
+	httpClient *http.Client
+
+	// The target platform of binaries, the default is the platform that gtctl runs on.
+	os   string
+	arch string
+
+	// workingDir is the working directory of gtctl that contains the mirror config and the cache.
+	workingDir string
+
+	// cacheDir is the directory to cache the resolved versions and chart index, the empty dir disables the cache.
+	cacheDir string
+
+	// cacheTTL is the time-to-live of the cached versions and chart index.
+	cacheTTL time.Duration
+
+	// If refresh is true, the unexpired cache will be ignored.
+	refresh bool
+
+	// If offline is true, only the cached versions and artifacts are used, nothing is resolved or downloaded.
+	offline bool
+
+	// customProviders are the source providers registered by options.
+	customProviders []SourceProvider
+
+	// sourceOrder is the resolution order of source providers, the empty order means using the order in mirror config.
+	sourceOrder []string
+
+	// providers are the source providers in the resolution order.
+	providers []SourceProvider
 }
 
 var _ Manager = &manager{}
 
 type Option func(*manager)
 
+// WithWorkingDir sets the working directory of gtctl, the mirror config and the cache are in it.
+func WithWorkingDir(dir string) Option {
+	return func(m *manager) {
+		m.workingDir = dir
+		m.cacheDir = filepath.Join(dir, DefaultCacheDir)
+	}
+}
+
+// WithOffline makes the manager only use the cached versions and artifacts, it fails fast if they are not cached.
+func WithOffline(offline bool) Option {
+	return func(m *manager) {
+		m.offline = offline
+	}
+}
+
+// WithPlatform sets the default target platform of binaries, for example, 'linux' and 'arm64'.
+func WithPlatform(os, arch string) Option {
+	return func(m *manager) {
+		m.os = os
+		m.arch = arch
+	}
+}
+
 // NewManager creates a new Manager with workingDir, logger and other options.
 func NewManager(logger logger.Logger, opts ...Option) (Manager, error) {
 	m := &manager{
-		logger: logger,
+		logger:        logger,
+		retryInterval: defaultDownloadRetryInterval,
+		httpClient:    newHTTPClient(),
+		os:            runtime.GOOS,
+		arch:          runtime.GOARCH,
 	}
 
+	// The cache is disabled if the home directory is unknown.
+	if workingDir, err := defaultWorkingDir(); err == nil {
+		m.workingDir = workingDir
+		m.cacheDir = filepath.Join(workingDir, DefaultCacheDir)
+	}
+
+	cacheTTL, err := defaultCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	m.cacheTTL = cacheTTL
+
 	for _, opt := range opts {
 		opt(m)
 	}
 
+	if m.mirrors == nil {
+		mirrors, err := LoadMirrors(m.workingDir)
+		if err != nil {
+			return nil, err
+		}
+		m.mirrors = mirrors
+	}
+
+	providers, err := m.newSourceProviders()
+	if err != nil {
+		return nil, err
+	}
+	m.providers = providers
+
 	return m, nil
 }
 
-func (m *manager) NewSource(name, version string, typ ArtifactType, fromCNRegion bool) (*Source, error) {
+func (m *manager) NewSource(name, version string, typ ArtifactType, fromCNRegion bool, opts ...SourceOption) (*Source, error) {
 	src := &Source{
 		Name:         name,
 		Type:         typ,
 		Version:      version,
 		FromCNRegion: fromCNRegion,
+		Platform:     Platform{OS: m.os, Arch: m.arch},
+	}
+	for _, opt := range opts {
+		opt(src)
 	}
 
-	if version == LatestVersionTag || len(version) == 0 {
-		latestVersion, err := m.resolveLatestVersion(typ, name, fromCNRegion)
-		if err != nil {
+	// The binary of OCI reference is pulled from the registry as it is.
+	if typ == ArtifactTypeBinary && IsOCIReference(version) {
+		if m.offline {
+			return nil, fmt.Errorf("the %s '%s' of '%s' can't be resolved offline", typ, name, version)
+		}
+		if err := m.newOCIBinarySource(context.TODO(), src); err != nil {
 			return nil, err
 		}
-		src.Version = latestVersion
+		return src, nil
 	}
 
-	if src.Type == ArtifactTypeChart {
-		src.FileName = m.chartFileName(src.Name, src.Version)
-		if src.FromCNRegion {
-			// The download URL example: 'https://downloads.greptime.cn/releases/charts/etcd/9.2.0/etcd-9.2.0.tgz'.
-			src.URL = fmt.Sprintf("%s/%s/%s/%s", GreptimeCNCharts, src.Name, src.Version, src.FileName)
-		} else {
-			// Specify the OCI registry URL for the etcd chart.
-			if src.Name == EtcdChartName {
-				// The download URL example: 'oci://registry-1.docker.io/bitnamicharts/etcd:9.2.0'.
-				src.URL = EtcdOCIRegistry
-			} else {
-				// The download URL example: 'https://github.com/GreptimeTeam/helm-charts/releases/download/greptimedb-0.1.1-alpha.3/greptimedb-0.1.1-alpha.3.tgz'.
-				src.URL = fmt.Sprintf("%s/%s/%s", GreptimeChartReleaseDownloadURL, strings.TrimSuffix(src.FileName, fileutils.TgzExtension), src.FileName)
-			}
-		}
+	resolvedVersion, err := m.resolveVersion(typ, name, version, fromCNRegion)
+	if err != nil {
+		return nil, err
 	}
+	src.Version = resolvedVersion
 
-	if src.Type == ArtifactTypeBinary {
-		if src.Name == EtcdBinName {
-			downloadURL, err := m.etcdBinaryDownloadURL(src.Version, src.FromCNRegion)
-			if err != nil {
-				return nil, err
-			}
-			src.URL = downloadURL
-			src.FileName = path.Base(src.URL)
-		}
-
-		if src.Name == GreptimeBinName {
-			specificVersion := src.Version
-			if specificVersion == LatestVersionTag && !src.FromCNRegion {
-				// Get the latest version of the latest greptime binary.
-				latestVersion, err := m.latestGitHubReleaseVersion(GreptimeGitHubOrg, GreptimeDBGithubRepo)
-				if err != nil {
-					return nil, err
-				}
-				specificVersion = latestVersion
-			}
-
-			downloadURL, err := m.greptimeBinaryDownloadURL(specificVersion, src.FromCNRegion)
-			if err != nil {
-				return nil, err
-			}
-			src.URL = downloadURL
-			src.FileName = path.Base(src.URL)
+	for _, provider := range m.providers {
+		ok, err := provider.Provide(src)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			m.logger.V(3).Infof("The %s '%s' is provided by '%s': '%s'", src.Type, src.Name, provider.Name(), src.URL)
+			return src, nil
 		}
 	}
 
-	return src, nil
+	return nil, fmt.Errorf("no source provider for %s '%s'", typ, name)
 }
 
 func (m *manager) DownloadTo(ctx context.Context, from *Source, destDir string, opts *DownloadOptions) (string, error) {
+	// Ensure the directories of the destDir exist.
+	if err := fileutils.EnsureDir(destDir); err != nil {
+		return "", err
+	}
+
+	// Serialize the gtctl processes that download the same artifact, so that they can share the cache safely.
+	unlock, err := m.lockDir(ctx, destDir)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
 	artifactFile := filepath.Join(destDir, from.FileName)
 	shouldDownload := true
-	if opts.EnableCache {
-		_, err := os.Stat(artifactFile)
+	if opts.EnableCache || m.offline {
+		// Only the complete and unmodified artifact can be reused.
+		valid, verified, err := m.isCacheValid(artifactFile)
+		if err != nil {
+			return "", err
+		}
 
-		// If the file exists, skip downloading.
-		if err == nil {
+		if valid {
 			m.logger.V(3).Infof("The artifact file '%s' already exists, skip downloading.", artifactFile)
 			shouldDownload = false
 		}
 
-		// Other error happened, return it.
-		if err != nil && !os.IsNotExist(err) {
-			return "", err
+		// The checksum may be published after the artifact is downloaded.
+		if valid && !verified && !m.offline {
+			m.logger.V(3).Infof("The cached artifact '%s' is not verified, verify it again.", artifactFile)
+			if err := m.verifyArtifact(ctx, from, artifactFile); err != nil {
+				return "", err
+			}
 		}
 	}
 
+	if shouldDownload && m.offline {
+		return "", fmt.Errorf("the %s '%s' of version '%s' is not cached, it can't be downloaded offline", from.Type, from.Name, from.Version)
+	}
+
 	if shouldDownload {
 		m.logger.V(3).Infof("Downloading artifact from '%s' to '%s'", from.URL, destDir)
 
-		// Ensure the directories of the destDir exist.
-		if err := fileutils.EnsureDir(destDir); err != nil {
-			return "", err
-		}
-
-		// Download the helm chart from OCI registry.
 		if registry.IsOCI(from.URL) && from.Type == ArtifactTypeChart {
+			// Download the helm chart from OCI registry.
 			if err := m.downloadFromOCI(from.URL, from.Version, destDir); err != nil {
 				return "", err
 			}
-			return artifactFile, nil
+		} else if registry.IsOCI(from.URL) && from.Type == ArtifactTypeBinary {
+			// Pull the package of binary from OCI registry.
+			if err := m.downloadBinaryFromOCI(ctx, from, artifactFile); err != nil {
+				return "", err
+			}
+		} else {
+			if err := m.downloadFromHTTP(ctx, from.URL, artifactFile, opts.Spinner); err != nil {
+				return "", err
+			}
 		}
 
-		if err := m.downloadFromHTTP(ctx, from.URL, artifactFile); err != nil {
+		if err := m.verifyArtifact(ctx, from, artifactFile); err != nil {
 			return "", err
 		}
 	}
 
-	if from.Type == ArtifactTypeBinary {
+	if from.Type == ArtifactTypeBinary && !opts.SkipInstall {
 		if opts.BinaryInstallDir == "" {
 			return "", fmt.Errorf("binary install dir is empty")
 		}
-		if err := m.installBinaries(artifactFile, opts.BinaryInstallDir); err != nil {
+		binaries := from.binaries()
+		if err := m.installBinaries(artifactFile, opts.BinaryInstallDir, binaries); err != nil {
 			return "", err
 		}
-		return filepath.Join(filepath.Dir(destDir), "bin", from.Name), nil
+		return filepath.Join(opts.BinaryInstallDir, binaries[0]), nil
 	}
 
 	return artifactFile, nil
 }
 
-func (m *manager) downloadFromHTTP(ctx context.Context, httpURL string, dest string) error {
-	httpClient := &http.Client{}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL, nil)
-	if err != nil {
-		return err
-	}
-
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed, status code: %d", resp.StatusCode)
-	}
-	defer resp.Body.Close()
+func (m *manager) ListVersions(ctx context.Context, name string, typ ArtifactType) ([]string, error) {
+	var versions []string
+	switch typ {
+	case ArtifactTypeChart:
+		indexURL := GreptimeChartIndexURL
+		if len(m.mirrors.ChartIndex) > 0 {
+			indexURL = m.mirrors.ChartIndex
+		}
+		indexFile, err := m.chartIndexFile(ctx, indexURL)
+		if err != nil {
+			return nil, err
+		}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
+		// The chart versions in index file are already sorted.
+		for _, chartVersion := range indexFile.Entries[name] {
+			versions = append(versions, chartVersion.Version)
+		}
+	case ArtifactTypeBinary:
+		org, repo := GreptimeGitHubOrg, GreptimeDBGithubRepo
+		if name == EtcdBinName {
+			org, repo = EtcdGitHubOrg, EtcdGithubRepo
+		}
 
-	file, err := os.Create(dest)
-	if err != nil {
-		return err
+		// The releases are sorted by the creation date and only the recent releases are listed.
+		releases, _, err := m.githubClient().Repositories.ListReleases(ctx, org, repo, &github.ListOptions{PerPage: maxListedReleases})
+		if err != nil {
+			return nil, githubError(err)
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue
+			}
+			versions = append(versions, release.GetTagName())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported artifact type: %s", string(typ))
 	}
 
-	_, err = file.Write(data)
-	if err != nil {
-		return err
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions of %s '%s' found", typ, name)
 	}
 
-	return nil
+	return versions, nil
 }
 
 func (m *manager) downloadFromOCI(registryURL, version, dest string) error {
@@ -297,18 +451,7 @@ func (m *manager) downloadFromOCI(registryURL, version, dest string) error {
 
 // chartIndexFile returns the index file of the chart. We use the index file to get the specific version of the latest chart.
 func (m *manager) chartIndexFile(ctx context.Context, indexURL string) (*repo.IndexFile, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	rsp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer rsp.Body.Close()
-
-	data, err := io.ReadAll(rsp.Body)
+	data, err := m.chartIndexData(ctx, indexURL)
 	if err != nil {
 		return nil, err
 	}
@@ -344,6 +487,52 @@ func (m *manager) chartIndexFile(ctx context.Context, indexURL string) (*repo.In
 	return indexFile, nil
 }
 
+// chartIndexData returns the content of chart index, the remote index is cached.
+func (m *manager) chartIndexData(ctx context.Context, indexURL string) ([]byte, error) {
+	// The local index file doesn't need to be cached.
+	if hasScheme(indexURL, "file://") {
+		return m.downloadChartIndex(ctx, indexURL)
+	}
+
+	if !m.refresh {
+		if data, ok := m.cachedChartIndex(indexURL, false); ok {
+			return data, nil
+		}
+	}
+
+	data, err := m.downloadChartIndex(ctx, indexURL)
+	if err != nil {
+		stale, ok := m.cachedChartIndex(indexURL, true)
+		if !ok {
+			return nil, err
+		}
+		m.logger.Warnf("Failed to download the chart index '%s': %v, use the cached one.", indexURL, err)
+		return stale, nil
+	}
+	m.cacheChartIndex(indexURL, data)
+
+	return data, nil
+}
+
+func (m *manager) downloadChartIndex(ctx context.Context, indexURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download chart index '%s': %s", indexURL, rsp.Status)
+	}
+
+	return io.ReadAll(rsp.Body)
+}
+
 // latestChartVersion returns the latest chart version.
 func (m *manager) latestChartVersion(indexFile *repo.IndexFile, chartName string) (*repo.ChartVersion, error) {
 	if versions, ok := indexFile.Entries[chartName]; ok {
@@ -361,96 +550,69 @@ func (m *manager) latestChartVersion(indexFile *repo.IndexFile, chartName string
 	return nil, fmt.Errorf("chart %s not found", chartName)
 }
 
-func (m *manager) chartFileName(chartName, version string) string {
-	return fmt.Sprintf("%s-%s.tgz", chartName, version)
-}
-
 // latestGitHubReleaseVersion returns the latest GitHub release version. It's used to locate the latest version of the latest greptime binary.
 func (m *manager) latestGitHubReleaseVersion(org, repo string) (string, error) {
-	client := github.NewClient(nil)
-	release, _, err := client.Repositories.GetLatestRelease(context.Background(), org, repo)
+	release, _, err := m.githubClient().Repositories.GetLatestRelease(context.Background(), org, repo)
 	if err != nil {
-		return "", err
+		return "", githubError(err)
 	}
 	return *release.TagName, nil
 }
 
-func (m *manager) etcdBinaryDownloadURL(version string, fromCNRegion bool) (string, error) {
-	var ext string
-
-	switch runtime.GOOS {
-	case "darwin":
-		ext = fileutils.ZipExtension
-	case "linux":
-		ext = fileutils.TarGzExtension
-	default:
-		return "", fmt.Errorf("unsupported OS: %s", runtime.GOOS)
-	}
-
-	var downloadURL string
-	if fromCNRegion {
-		downloadURL = EtcdCNBinaries
-	} else {
-		downloadURL = fmt.Sprintf("https://github.com/%s/%s/releases/download", EtcdGitHubOrg, EtcdGithubRepo)
-	}
-
-	// For the function stability, we always use the specific version of etcd.
-	return fmt.Sprintf("%s/%s/etcd-%s-%s-%s%s", downloadURL, version, version, runtime.GOOS, runtime.GOARCH, ext), nil
-}
-
-func (m *manager) greptimeBinaryDownloadURL(version string, fromCNRegion bool) (string, error) {
-	newVersion, err := isBreakingVersion(version)
+// installBinaries installs the expected binaries in the package to the installDir.
+// The binaries are extracted in a staging directory and then renamed to the installDir atomically,
+// the old binaries in the installDir are kept until the new ones are in place. The installDir is reused
+// if it's installed from the same package.
+func (m *manager) installBinaries(downloadFile, installDir string, binaries []string) error {
+	checksum, err := os.ReadFile(downloadFile + ChecksumFileSuffix)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	var packageName string
-	if newVersion {
-		packageName = fmt.Sprintf("greptime-%s-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH, version)
-	} else {
-		packageName = fmt.Sprintf("greptime-%s-%s.tgz", runtime.GOOS, runtime.GOARCH)
+	marker, err := os.ReadFile(filepath.Join(installDir, installedMarkerFile))
+	if err == nil && bytes.Equal(marker, checksum) && verifyBinaries(installDir, binaries) == nil {
+		m.logger.V(3).Infof("The binaries of '%s' are already installed in '%s'", downloadFile, installDir)
+		return nil
 	}
 
-	var downloadURL string
-	if fromCNRegion {
-		downloadURL = GreptimeDBCNBinaries
-	} else {
-		downloadURL = fmt.Sprintf("https://github.com/%s/%s/releases/download", GreptimeGitHubOrg, GreptimeDBGithubRepo)
+	// The staging directory is in the same filesystem as the installDir, so that it can be renamed.
+	parentDir := filepath.Dir(installDir)
+	if err := fileutils.EnsureDir(parentDir); err != nil {
+		return err
 	}
-
-	return fmt.Sprintf("%s/%s/%s", downloadURL, version, packageName), nil
-}
-
-// installBinaries installs the binaries to the installDir.
-func (m *manager) installBinaries(downloadFile, installDir string) error {
-	if err := fileutils.EnsureDir(installDir); err != nil {
+	stagingDir, err := os.MkdirTemp(parentDir, filepath.Base(installDir)+".staging-")
+	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(stagingDir)
 
-	tempDir, err := os.MkdirTemp("/tmp", "gtctl-")
-	if err != nil {
+	extractDir := filepath.Join(stagingDir, "extract")
+	if err := fileutils.Uncompress(downloadFile, extractDir); err != nil {
 		return err
 	}
-	defer os.RemoveAll(tempDir)
 
-	if err := fileutils.Uncompress(downloadFile, tempDir); err != nil {
+	binDir := filepath.Join(stagingDir, "bin")
+	if err := fileutils.EnsureDir(binDir); err != nil {
 		return err
 	}
 
-	m.logger.V(3).Infof("Installing binaries '%s' to '%s'", downloadFile, installDir)
+	m.logger.V(3).Infof("Installing binaries %v of '%s' to '%s'", binaries, downloadFile, installDir)
 
-	if err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
+	found := make(map[string]string)
+	if !fileutils.IsArchive(downloadFile) || fileutils.IsGzippedFile(downloadFile) {
+		// The package is the binary itself or the gzipped one, which may be named differently from the binary.
+		if len(binaries) != 1 {
+			return fmt.Errorf("the package '%s' is a single binary, but %d binaries %v are expected", downloadFile, len(binaries), binaries)
+		}
+		found[binaries[0]] = filepath.Join(extractDir, strings.TrimSuffix(filepath.Base(downloadFile), fileutils.GzExtension))
+	} else if err := filepath.Walk(extractDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if info.Mode().IsRegular() && (info.Mode()&0111 != 0) { // Move the executable file to the installDir.
-			newFilePath := filepath.Join(installDir, info.Name())
-			if path != newFilePath {
-				if err := os.Rename(path, newFilePath); err != nil {
-					return err
-				}
-			}
+		// The first regular file that has the same name as the binary is used.
+		if _, ok := found[info.Name()]; !ok && info.Mode().IsRegular() {
+			found[info.Name()] = path
 		}
 
 		return nil
@@ -458,11 +620,70 @@ func (m *manager) installBinaries(downloadFile, installDir string) error {
 		return err
 	}
 
+	for _, binary := range binaries {
+		path, ok := found[binary]
+		if !ok {
+			return fmt.Errorf("binary '%s' is not found in the package '%s'", binary, downloadFile)
+		}
+		if err := os.Rename(path, filepath.Join(binDir, binary)); err != nil {
+			return err
+		}
+	}
+	if err := verifyBinaries(binDir, binaries); err != nil {
+		return fmt.Errorf("invalid package '%s': %v", downloadFile, err)
+	}
+
+	// The marker is written at last, so the installDir without it is regarded as incomplete.
+	if err := os.WriteFile(filepath.Join(binDir, installedMarkerFile), checksum, 0644); err != nil {
+		return err
+	}
+
+	// The old binaries are moved aside instead of being removed before the new ones take their place,
+	// so that they are still there if the new ones fail to be installed. They are removed along with the
+	// staging directory.
+	oldDir := filepath.Join(stagingDir, "old")
+	if err := os.Rename(installDir, oldDir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Rename(binDir, installDir); err != nil {
+		if restoreErr := os.Rename(oldDir, installDir); restoreErr != nil && !os.IsNotExist(restoreErr) {
+			m.logger.V(3).Infof("failed to restore the old binaries in '%s': %v", installDir, restoreErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// verifyBinaries verifies the binaries in the dir are executable regular files.
+func verifyBinaries(dir string, binaries []string) error {
+	for _, binary := range binaries {
+		info, err := os.Lstat(filepath.Join(dir, binary))
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+			return fmt.Errorf("binary '%s' is not an executable file", binary)
+		}
+	}
 	return nil
 }
 
 // resolveLatestVersion resolves the latest tag to the specific version.
 func (m *manager) resolveLatestVersion(typ ArtifactType, name string, fromCNRegion bool) (string, error) {
+	for _, provider := range m.providers {
+		if p, ok := provider.(LatestVersionProvider); ok {
+			if version, ok, err := p.LatestVersion(context.TODO(), typ, name, fromCNRegion); ok || err != nil {
+				return version, err
+			}
+		}
+	}
+
+	if version, ok, err := m.resolveLatestVersionFromMirrors(typ, name); ok || err != nil {
+		return version, err
+	}
+
 	if fromCNRegion {
 		return m.getVersionInfoFromS3(typ, name, false)
 	}
@@ -512,38 +733,54 @@ func (m *manager) getVersionInfoFromS3(typ ArtifactType, name string, nightly bo
 		return "", fmt.Errorf("unsupported artifact type: %s", string(typ))
 	}
 
+	return m.latestVersionFromURL(latestVersionInfoURL)
+}
+
+// resolveLatestVersionFromMirrors resolves the latest version from the mirrors.
+// It returns false if there is no mirror for the artifact.
+func (m *manager) resolveLatestVersionFromMirrors(typ ArtifactType, name string) (string, bool, error) {
+	switch {
+	case typ == ArtifactTypeChart && len(m.mirrors.ChartIndex) > 0:
+		indexFile, err := m.chartIndexFile(context.TODO(), m.mirrors.ChartIndex)
+		if err != nil {
+			return "", true, err
+		}
+		chartVersion, err := m.latestChartVersion(indexFile, name)
+		if err != nil {
+			return "", true, err
+		}
+		return chartVersion.Version, true, nil
+	case typ == ArtifactTypeChart && len(m.mirrors.Charts) > 0:
+		version, err := m.latestVersionFromURL(fmt.Sprintf("%s/%s/latest-version.txt", strings.TrimSuffix(m.mirrors.Charts, "/"), name))
+		return version, true, err
+	case typ == ArtifactTypeBinary && name == GreptimeBinName && len(m.mirrors.GreptimeBinaries) > 0:
+		version, err := m.latestVersionFromURL(fmt.Sprintf("%s/latest-version.txt", strings.TrimSuffix(m.mirrors.GreptimeBinaries, "/")))
+		return version, true, err
+	default:
+		return "", false, nil
+	}
+}
+
+// latestVersionFromURL reads the latest version from the 'latest-version.txt' file.
+func (m *manager) latestVersionFromURL(latestVersionInfoURL string) (string, error) {
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, latestVersionInfoURL, nil)
 	if err != nil {
 		return "", err
 	}
 
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
+	resp, err := m.httpClient.Do(req)
 	if err != nil {
 		return "", err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("get latest info from '%s' failed, status code: %d", latestVersionInfoURL, resp.StatusCode)
 	}
-	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimRight(string(data), "\n"), nil
-}
-
-// BreakingChangeVersion is the version that the download URL of the greptime binary is changed.
-const BreakingChangeVersion = "v0.4.0-nightly-20230802"
-
-// TODO(zyy17): This function is just a temporary solution. We will remove it after the download URL of the greptime binary is stable.
-func isBreakingVersion(version string) (bool, error) {
-	newVersion, err := semverutils.Compare(version, BreakingChangeVersion)
-	if err != nil {
-		return false, err
-	}
-
-	return newVersion || version == BreakingChangeVersion, nil
+	return strings.TrimSpace(string(data)), nil
 }
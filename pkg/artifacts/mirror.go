@@ -0,0 +1,173 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MirrorConfigEnv is the environment variable of the mirror config file path.
+	MirrorConfigEnv = "GTCTL_MIRROR_CONFIG"
+
+	// DefaultMirrorConfigFile is the default mirror config file in the working directory of gtctl.
+	DefaultMirrorConfigFile = "mirrors.yaml"
+
+	// The environment variables that override the mirrors in config file.
+	MirrorGreptimeBinariesEnv  = "GTCTL_MIRROR_GREPTIME_BINARIES"
+	MirrorEtcdBinariesEnv      = "GTCTL_MIRROR_ETCD_BINARIES"
+	MirrorChartIndexEnv        = "GTCTL_MIRROR_CHART_INDEX"
+	MirrorChartsEnv            = "GTCTL_MIRROR_CHARTS"
+	MirrorEtcdChartRegistryEnv = "GTCTL_MIRROR_ETCD_CHART_REGISTRY"
+	MirrorLocalDirEnv          = "GTCTL_MIRROR_LOCAL_DIR"
+)
+
+// Mirrors is the config of mirrors that replace the default download locations of artifacts.
+// All the mirrors have the same layout as 'downloads.greptime.cn', and can be 'http://', 'https://' or 'file://' URLs.
+// The empty mirror means using the default location. The released versions are listed in 'versions.txt', one
+// version per line, next to the 'latest-version.txt', which is used to resolve 'stable' and the version ranges.
+type Mirrors struct {
+	// GreptimeBinaries is the base URL of greptime binaries, for example, '<base>/v0.4.0/greptime-linux-amd64-v0.4.0.tar.gz'.
+	// The latest version is read from '<base>/latest-version.txt'.
+	GreptimeBinaries string `json:"greptimeBinaries,omitempty"`
+
+	// EtcdBinaries is the base URL of etcd binaries, for example, '<base>/v3.5.7/etcd-v3.5.7-linux-amd64.tar.gz'.
+	EtcdBinaries string `json:"etcdBinaries,omitempty"`
+
+	// ChartIndex is the URL of the index file of GreptimeDB charts, it's used to resolve the latest version of charts.
+	ChartIndex string `json:"chartIndex,omitempty"`
+
+	// Charts is the base URL of chart tarballs, for example, '<base>/greptimedb-cluster/0.1.2/greptimedb-cluster-0.1.2.tgz'.
+	// If ChartIndex is empty, the latest version is read from '<base>/<chart>/latest-version.txt'.
+	// The etcd chart is also downloaded from it if EtcdChartRegistry is empty.
+	Charts string `json:"charts,omitempty"`
+
+	// EtcdChartRegistry is the OCI registry of etcd chart, for example, 'oci://registry.example.com/bitnamicharts/etcd'.
+	EtcdChartRegistry string `json:"etcdChartRegistry,omitempty"`
+
+	// LocalDir is the local directory that has the same layout as 'downloads.greptime.cn'.
+	// The artifacts that exist in it are preferred by the 'local' source provider.
+	LocalDir string `json:"localDir,omitempty"`
+
+	// SourceOrder is the resolution order of source providers, the default is 'local,mirror,greptime-cn,github,oci'.
+	SourceOrder []string `json:"sourceOrder,omitempty"`
+}
+
+// WithMirrors sets the mirrors of artifacts, it overrides the mirrors from config file and environment variables.
+func WithMirrors(mirrors *Mirrors) Option {
+	return func(m *manager) {
+		m.mirrors = mirrors
+	}
+}
+
+// LoadMirrors loads the mirrors from the config file and the environment variables.
+// The config file is specified by 'GTCTL_MIRROR_CONFIG', or '${workingDir}/mirrors.yaml' if it exists.
+// The empty workingDir means the working directory in 'GTCTL_HOME' or the home directory of current user.
+func LoadMirrors(workingDir string) (*Mirrors, error) {
+	mirrors := &Mirrors{}
+
+	configFile, explicit := os.LookupEnv(MirrorConfigEnv)
+	if !explicit {
+		if len(workingDir) == 0 {
+			dir, err := defaultWorkingDir()
+			if err != nil {
+				return nil, err
+			}
+			workingDir = dir
+		}
+		configFile = filepath.Join(workingDir, DefaultMirrorConfigFile)
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil && (explicit || !os.IsNotExist(err)) {
+		return nil, fmt.Errorf("failed to read mirror config '%s': %v", configFile, err)
+	}
+	if err == nil {
+		if err := yaml.UnmarshalStrict(data, mirrors); err != nil {
+			return nil, fmt.Errorf("invalid mirror config '%s': %v", configFile, err)
+		}
+	}
+
+	for env, field := range map[string]*string{
+		MirrorGreptimeBinariesEnv:  &mirrors.GreptimeBinaries,
+		MirrorEtcdBinariesEnv:      &mirrors.EtcdBinaries,
+		MirrorChartIndexEnv:        &mirrors.ChartIndex,
+		MirrorChartsEnv:            &mirrors.Charts,
+		MirrorEtcdChartRegistryEnv: &mirrors.EtcdChartRegistry,
+		MirrorLocalDirEnv:          &mirrors.LocalDir,
+	} {
+		if value := os.Getenv(env); len(value) > 0 {
+			*field = value
+		}
+	}
+
+	if value := os.Getenv(SourceOrderEnv); len(value) > 0 {
+		mirrors.SourceOrder = strings.Split(value, ",")
+	}
+
+	if err := mirrors.Validate(); err != nil {
+		return nil, err
+	}
+
+	return mirrors, nil
+}
+
+// Validate validates the schemes of mirrors and the local directory.
+func (m *Mirrors) Validate() error {
+	for name, url := range map[string]string{
+		"greptimeBinaries": m.GreptimeBinaries,
+		"etcdBinaries":     m.EtcdBinaries,
+		"chartIndex":       m.ChartIndex,
+		"charts":           m.Charts,
+	} {
+		if len(url) > 0 && !hasScheme(url, "http://", "https://", "file://") {
+			return fmt.Errorf("invalid mirror '%s' of '%s', the scheme should be 'http', 'https' or 'file'", url, name)
+		}
+	}
+
+	if len(m.EtcdChartRegistry) > 0 && !hasScheme(m.EtcdChartRegistry, "oci://") {
+		return fmt.Errorf("invalid mirror '%s' of 'etcdChartRegistry', the scheme should be 'oci'", m.EtcdChartRegistry)
+	}
+
+	if len(m.LocalDir) > 0 && !filepath.IsAbs(m.LocalDir) {
+		return fmt.Errorf("invalid mirror '%s' of 'localDir', it should be an absolute path", m.LocalDir)
+	}
+
+	return nil
+}
+
+func hasScheme(url string, schemes ...string) bool {
+	for _, scheme := range schemes {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// newHTTPClient creates the http client that also supports 'file://' URLs.
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/home"
+)
+
+func TestLoadMirrors(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, DefaultMirrorConfigFile)
+	config := `
+greptimeBinaries: http://mirror.internal/greptimedb
+charts: file:///data/charts
+`
+	if err := os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The config file in the working directory is loaded by default.
+	t.Setenv(MirrorConfigEnv, "")
+	os.Unsetenv(MirrorConfigEnv)
+	mirrors, err := LoadMirrors(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirrors.GreptimeBinaries != "http://mirror.internal/greptimedb" || mirrors.Charts != "file:///data/charts" {
+		t.Errorf("unexpected mirrors from working directory: %v", *mirrors)
+	}
+
+	// The empty working directory means the one in 'GTCTL_HOME'.
+	homeDir := filepath.Join(tempDir, "home")
+	if err := os.MkdirAll(filepath.Join(homeDir, home.BaseDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, home.BaseDir, DefaultMirrorConfigFile), []byte("charts: file:///home/charts\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(home.DirEnv, homeDir)
+	if mirrors, err = LoadMirrors(""); err != nil {
+		t.Fatal(err)
+	}
+	if mirrors.Charts != "file:///home/charts" {
+		t.Errorf("unexpected mirrors from GTCTL_HOME: %v", *mirrors)
+	}
+
+	t.Setenv(MirrorConfigEnv, configFile)
+	t.Setenv(MirrorChartsEnv, "http://mirror.internal/charts")
+
+	mirrors, err = LoadMirrors("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Mirrors{
+		GreptimeBinaries: "http://mirror.internal/greptimedb",
+		Charts:           "http://mirror.internal/charts",
+	}
+	if !reflect.DeepEqual(*mirrors, expected) {
+		t.Errorf("expected %v, got %v", expected, *mirrors)
+	}
+
+	// The invalid scheme should be rejected.
+	t.Setenv(MirrorEtcdChartRegistryEnv, "https://registry.internal/etcd")
+	if _, err = LoadMirrors(""); err == nil {
+		t.Errorf("expected error for invalid scheme")
+	}
+}
+
+func TestDownloadFromFileMirror(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Prepare the mirror that has the same layout as 'downloads.greptime.cn'.
+	mirrorDir := filepath.Join(tempDir, "mirror")
+	chartDir := filepath.Join(mirrorDir, GreptimeDBClusterChartName, "0.1.2")
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mirrorDir, GreptimeDBClusterChartName, "latest-version.txt"), []byte("0.1.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(chartDir, "greptimedb-cluster-0.1.2.tgz"), []byte("chart"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithMirrors(&Mirrors{Charts: "file://" + mirrorDir, EtcdBinaries: "http://mirror.internal/etcd/"}))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	src, err := m.NewSource(GreptimeDBClusterChartName, LatestVersionTag, ArtifactTypeChart, false)
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	if src.Version != "0.1.2" {
+		t.Errorf("expected version 0.1.2, got %s", src.Version)
+	}
+
+	artifactFile, err := m.DownloadTo(context.Background(), src, filepath.Join(tempDir, "dest"), &DownloadOptions{EnableCache: true})
+	if err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	data, err := os.ReadFile(artifactFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "chart" {
+		t.Errorf("unexpected content of artifact: %s", string(data))
+	}
+
+	src, err = m.NewSource(EtcdBinName, DefaultEtcdBinVersion, ArtifactTypeBinary, true)
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	if !strings.HasPrefix(src.URL, "http://mirror.internal/etcd/"+DefaultEtcdBinVersion+"/") {
+		t.Errorf("unexpected etcd download URL: %s", src.URL)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// packageNamingRule is the naming rule of the released packages of binary.
+// The '{os}', '{arch}' and '{version}' in the names are replaced by the target platform and the version.
+type packageNamingRule struct {
+	// Versions is the semantic version range that the rule applies to, the empty range matches all versions.
+	Versions string
+
+	// OS is the operating system that the rule applies to, the empty OS matches all operating systems.
+	OS string
+
+	// Package is the file name of the package.
+	Package string
+
+	// Checksum is the file name of the checksum of the package, it can be the checksum manifest of all packages.
+	Checksum string
+}
+
+// packageNamingRules are the naming rules of binaries, the first matched rule is used.
+var packageNamingRules = map[string][]packageNamingRule{
+	GreptimeBinName: {
+		{
+			// The version is added to the package name since 'v0.4.0-nightly-20230802'.
+			Versions: ">= 0.4.0-nightly-20230802",
+			Package:  "greptime-{os}-{arch}-{version}.tar.gz",
+			Checksum: "greptime-{os}-{arch}-{version}.sha256sum",
+		},
+		{
+			Versions: "< 0.4.0-nightly-20230802",
+			Package:  "greptime-{os}-{arch}.tgz",
+			Checksum: "greptime-{os}-{arch}.sha256sum",
+		},
+	},
+	EtcdBinName: {
+		{
+			OS:       "darwin",
+			Package:  "etcd-{version}-{os}-{arch}.zip",
+			Checksum: etcdChecksumManifest,
+		},
+		{
+			OS:       "linux",
+			Package:  "etcd-{version}-{os}-{arch}.tar.gz",
+			Checksum: etcdChecksumManifest,
+		},
+	},
+}
+
+// packageNames returns the file names of the package and its checksum of the binary for the version and platform.
+func packageNames(name, version string, platform Platform) (string, string, error) {
+	rules, ok := packageNamingRules[name]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported binary: %s", name)
+	}
+
+	for _, rule := range rules {
+		if len(rule.OS) > 0 && rule.OS != platform.OS {
+			continue
+		}
+
+		if len(rule.Versions) > 0 {
+			matched, err := matchVersions(rule.Versions, version)
+			if err != nil {
+				return "", "", err
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		replacer := strings.NewReplacer("{os}", platform.OS, "{arch}", platform.Arch, "{version}", version)
+		return replacer.Replace(rule.Package), replacer.Replace(rule.Checksum), nil
+	}
+
+	return "", "", fmt.Errorf("no package of %s '%s' for platform '%s/%s'", name, version, platform.OS, platform.Arch)
+}
+
+func matchVersions(versions, version string) (bool, error) {
+	constraint, err := semver.NewConstraint(versions)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false, fmt.Errorf("invalid version '%s': %v", version, err)
+	}
+
+	return constraint.Check(v), nil
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"testing"
+)
+
+func TestPackageNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		platform Platform
+		pkg      string
+		checksum string
+		err      bool
+	}{
+		{GreptimeBinName, "v0.4.0", Platform{"linux", "arm64"},
+			"greptime-linux-arm64-v0.4.0.tar.gz", "greptime-linux-arm64-v0.4.0.sha256sum", false},
+		{GreptimeBinName, "v0.4.0-nightly-20230802", Platform{"darwin", "amd64"},
+			"greptime-darwin-amd64-v0.4.0-nightly-20230802.tar.gz", "greptime-darwin-amd64-v0.4.0-nightly-20230802.sha256sum", false},
+		{GreptimeBinName, "v0.4.0-nightly-20230701", Platform{"linux", "amd64"},
+			"greptime-linux-amd64.tgz", "greptime-linux-amd64.sha256sum", false},
+		{GreptimeBinName, "v0.3.2", Platform{"darwin", "arm64"},
+			"greptime-darwin-arm64.tgz", "greptime-darwin-arm64.sha256sum", false},
+		{EtcdBinName, "v3.5.7", Platform{"darwin", "arm64"}, "etcd-v3.5.7-darwin-arm64.zip", "SHA256SUMS", false},
+		{EtcdBinName, "v3.5.7", Platform{"linux", "arm64"}, "etcd-v3.5.7-linux-arm64.tar.gz", "SHA256SUMS", false},
+		{EtcdBinName, "v3.5.7", Platform{"windows", "amd64"}, "", "", true},
+		{GreptimeBinName, "latest-build", Platform{"linux", "amd64"}, "", "", true},
+		{"vector", "v0.33.0", Platform{"linux", "amd64"}, "", "", true},
+	}
+	for _, tt := range tests {
+		pkg, checksum, err := packageNames(tt.name, tt.version, tt.platform)
+		if (err != nil) != tt.err {
+			t.Errorf("unexpected error of %s '%s': %v", tt.name, tt.version, err)
+			continue
+		}
+		if pkg != tt.pkg || checksum != tt.checksum {
+			t.Errorf("expected '%s' and '%s' of %s '%s', got '%s' and '%s'", tt.pkg, tt.checksum, tt.name, tt.version, pkg, checksum)
+		}
+	}
+}
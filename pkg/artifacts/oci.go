@@ -0,0 +1,234 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/remotes"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"helm.sh/helm/v3/pkg/registry"
+	orasauth "oras.land/oras-go/pkg/auth"
+	dockerauth "oras.land/oras-go/pkg/auth/docker"
+)
+
+const (
+	// ociScheme is the scheme of the OCI reference.
+	ociScheme = "oci://"
+
+	// dockerManifestListMediaType is the media type of the docker manifest list, which is the same as the OCI image index.
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// maxManifestSize is the max size of the manifest to read.
+	maxManifestSize = 4 << 20
+)
+
+// IsOCIReference returns true if the version of artifact is the OCI reference, for example, 'oci://registry.example.com/greptime:v0.4.0'.
+func IsOCIReference(version string) bool {
+	return registry.IsOCI(version)
+}
+
+// newOCIBinarySource fills the source of binary that is published as the OCI artifact, e.g. pushed by 'oras push'.
+// The version of the source is the tag of reference, and the file name is the title of the package layer for the target platform.
+func (m *manager) newOCIBinarySource(ctx context.Context, src *Source) error {
+	ref := src.Version
+	_, tag, err := splitOCIReference(ref)
+	if err != nil {
+		return err
+	}
+
+	_, layer, err := m.ociBinaryLayer(ctx, ref, "", src.Platform)
+	if err != nil {
+		return err
+	}
+
+	src.FileName = layer.Annotations[ocispec.AnnotationTitle]
+	if len(src.FileName) == 0 {
+		// The layer without title is regarded as the binary itself.
+		src.FileName = src.Name
+	}
+	src.Version = tag
+	src.URL = ref
+
+	return nil
+}
+
+// downloadBinaryFromOCI pulls the package layer of binary from the OCI registry to the artifactFile.
+// The registry credentials are read from the docker config, e.g. '~/.docker/config.json'.
+func (m *manager) downloadBinaryFromOCI(ctx context.Context, src *Source, artifactFile string) error {
+	m.logger.V(3).Infof("Pulling binary '%s' from OCI registry", src.URL)
+
+	fetcher, layer, err := m.ociBinaryLayer(ctx, src.URL, src.FileName, src.Platform)
+	if err != nil {
+		return err
+	}
+
+	rc, err := fetcher.Fetch(ctx, layer)
+	if err != nil {
+		return fmt.Errorf("failed to pull '%s' from '%s': %v", src.FileName, src.URL, err)
+	}
+	defer rc.Close()
+
+	tempFile, err := os.CreateTemp(filepath.Dir(artifactFile), filepath.Base(artifactFile)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	verifier := layer.Digest.Verifier()
+	if _, err := io.Copy(io.MultiWriter(tempFile, verifier), rc); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("digest mismatch of '%s' from '%s', expected '%s'", src.FileName, src.URL, layer.Digest)
+	}
+
+	return os.Rename(tempFile.Name(), artifactFile)
+}
+
+// ociBinaryLayer resolves the package layer of binary for the target platform from the OCI artifact. The artifact can be:
+//   - the image index of the platform-specific manifests;
+//   - the manifest whose layers are the packages of different platforms, which are titled like 'greptime-linux-amd64-v0.4.0.tar.gz';
+//   - the manifest that has only one layer.
+//
+// If the title is not empty, the layer that has the same title is returned.
+func (m *manager) ociBinaryLayer(ctx context.Context, ref, title string, platform Platform) (remotes.Fetcher, ocispec.Descriptor, error) {
+	host, _, err := splitOCIReference(ref)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+
+	client, err := dockerauth.NewClient()
+	if err != nil {
+		return nil, ocispec.Descriptor{}, fmt.Errorf("failed to load docker config: %v", err)
+	}
+
+	resolverOpts := []orasauth.ResolverOption{orasauth.WithResolverClient(m.httpClient)}
+	if isLoopbackHost(host) {
+		// The local registry is usually served by plain HTTP, the same as docker does.
+		resolverOpts = append(resolverOpts, orasauth.WithResolverPlainHTTP())
+	}
+	resolver, err := client.ResolverWithOpts(resolverOpts...)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+
+	name, desc, err := resolver.Resolve(ctx, strings.TrimPrefix(ref, ociScheme))
+	if err != nil {
+		return nil, ocispec.Descriptor{}, fmt.Errorf("failed to resolve '%s': %v", ref, err)
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+
+	if desc.MediaType == ocispec.MediaTypeImageIndex || desc.MediaType == dockerManifestListMediaType {
+		var index ocispec.Index
+		if err := fetchJSON(ctx, fetcher, desc, &index); err != nil {
+			return nil, ocispec.Descriptor{}, err
+		}
+
+		found := false
+		for _, manifest := range index.Manifests {
+			if manifest.Platform != nil && manifest.Platform.OS == platform.OS && manifest.Platform.Architecture == platform.Arch {
+				desc, found = manifest, true
+				break
+			}
+		}
+		if !found {
+			return nil, ocispec.Descriptor{}, fmt.Errorf("no manifest for platform '%s/%s' in '%s'", platform.OS, platform.Arch, ref)
+		}
+	}
+
+	var manifest ocispec.Manifest
+	if err := fetchJSON(ctx, fetcher, desc, &manifest); err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+
+	platformName := fmt.Sprintf("%s-%s", platform.OS, platform.Arch)
+	for _, layer := range manifest.Layers {
+		layerTitle := layer.Annotations[ocispec.AnnotationTitle]
+		if (len(title) > 0 && layerTitle == title) || (len(title) == 0 && strings.Contains(layerTitle, platformName)) {
+			return fetcher, layer, nil
+		}
+	}
+	if len(manifest.Layers) == 1 {
+		layerTitle := manifest.Layers[0].Annotations[ocispec.AnnotationTitle]
+		if len(title) == 0 || len(layerTitle) == 0 || layerTitle == title {
+			return fetcher, manifest.Layers[0], nil
+		}
+	}
+
+	return nil, ocispec.Descriptor{}, fmt.Errorf("no package for platform '%s' in '%s'", platformName, ref)
+}
+
+// fetchJSON fetches the manifest or index of desc and decodes it to v.
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor, v interface{}) error {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxManifestSize))
+	if err != nil {
+		return err
+	}
+	if err := desc.Digest.Validate(); err == nil && desc.Digest.Algorithm().FromBytes(data) != desc.Digest {
+		return fmt.Errorf("digest mismatch of manifest '%s'", desc.Digest)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// splitOCIReference splits the OCI reference like 'oci://registry.example.com/greptime:v0.4.0' to the host and the tag.
+func splitOCIReference(ref string) (string, string, error) {
+	if !IsOCIReference(ref) {
+		return "", "", fmt.Errorf("invalid OCI reference '%s', it should start with '%s'", ref, ociScheme)
+	}
+
+	repository := strings.TrimPrefix(ref, ociScheme)
+	slash := strings.Index(repository, "/")
+	colon := strings.LastIndex(repository, ":")
+	if slash <= 0 || colon < slash || colon == len(repository)-1 {
+		return "", "", fmt.Errorf("invalid OCI reference '%s', it should be like 'oci://<registry>/<repository>:<tag>'", ref)
+	}
+
+	return repository[:slash], repository[colon+1:], nil
+}
+
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
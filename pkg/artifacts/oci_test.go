@@ -0,0 +1,163 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestDownloadBinaryFromOCI(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Isolate the docker config.
+	t.Setenv("DOCKER_CONFIG", filepath.Join(tempDir, "docker"))
+
+	// The artifact that is pushed by 'oras push' with the packages of two platforms.
+	blobs := map[digest.Digest][]byte{}
+	var layers []ocispec.Descriptor
+	for _, fileName := range []string{"greptime-darwin-arm64-v0.4.0.tar.gz", "greptime-linux-amd64-v0.4.0.tar.gz"} {
+		pkg := newTestPackage(t, "greptime")
+		dgst := digest.FromBytes(pkg)
+		blobs[dgst] = pkg
+		layers = append(layers, ocispec.Descriptor{
+			MediaType:   "application/vnd.oci.image.layer.v1.tar",
+			Digest:      dgst,
+			Size:        int64(len(pkg)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: fileName},
+		})
+	}
+	config := []byte("{}")
+	blobs[digest.FromBytes(config)] = config
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.Descriptor{MediaType: "application/vnd.unknown.config.v1+json", Digest: digest.FromBytes(config), Size: int64(len(config))},
+		Layers:    layers,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDigest := digest.FromBytes(manifest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/gtctl/greptime/manifests/v0.4.0" || r.URL.Path == "/v2/gtctl/greptime/manifests/"+manifestDigest.String():
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", manifestDigest.String())
+			w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+			if r.Method == http.MethodGet {
+				w.Write(manifest)
+			}
+		case strings.HasPrefix(r.URL.Path, "/v2/gtctl/greptime/blobs/"):
+			blob, ok := blobs[digest.Digest(strings.TrimPrefix(r.URL.Path, "/v2/gtctl/greptime/blobs/"))]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+			w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithMirrors(&Mirrors{}), WithPlatform("linux", "amd64"))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	ref := "oci://" + strings.TrimPrefix(server.URL, "http://") + "/gtctl/greptime:v0.4.0"
+	src, err := m.NewSource(GreptimeBinName, ref, ArtifactTypeBinary, false)
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	if src.Version != "v0.4.0" || src.FileName != "greptime-linux-amd64-v0.4.0.tar.gz" {
+		t.Errorf("unexpected source: %+v", src)
+	}
+
+	destDir := filepath.Join(tempDir, "v0.4.0", "pkg")
+	binPath, err := m.DownloadTo(context.Background(), src, destDir, &DownloadOptions{
+		EnableCache:      true,
+		BinaryInstallDir: filepath.Join(tempDir, "v0.4.0", "bin"),
+	})
+	if err != nil {
+		t.Fatalf("failed to download: %v", err)
+	}
+	info, err := os.Stat(binPath)
+	if err != nil {
+		t.Fatalf("failed to stat binary: %v", err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Errorf("binary file is not executable")
+	}
+
+	// The artifact without the package of the platform is rejected.
+	m, err = NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithMirrors(&Mirrors{}), WithPlatform("linux", "arm64"))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+	if _, err := m.NewSource(GreptimeBinName, ref, ArtifactTypeBinary, false); err == nil {
+		t.Errorf("expected error for the missing platform")
+	}
+}
+
+func TestSplitOCIReference(t *testing.T) {
+	tests := []struct {
+		ref  string
+		host string
+		tag  string
+		err  bool
+	}{
+		{"oci://registry.example.com/greptime:v0.4.0", "registry.example.com", "v0.4.0", false},
+		{"oci://localhost:5000/gtctl/etcd:v3.5.7", "localhost:5000", "v3.5.7", false},
+		{"oci://localhost:5000/greptime", "", "", true},
+		{"oci://greptime:v0.4.0", "", "", true},
+		{"registry.example.com/greptime:v0.4.0", "", "", true},
+	}
+	for _, tt := range tests {
+		host, tag, err := splitOCIReference(tt.ref)
+		if (err != nil) != tt.err {
+			t.Errorf("unexpected error of '%s': %v", tt.ref, err)
+			continue
+		}
+		if host != tt.host || tag != tt.tag {
+			t.Errorf("expected '%s' and '%s' of '%s', got '%s' and '%s'", tt.host, tt.tag, tt.ref, host, tag)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// LocalSourceProvider provides the artifacts that exist in the local directory.
+	LocalSourceProvider = "local"
+
+	// MirrorSourceProvider provides the artifacts from the mirrors.
+	MirrorSourceProvider = "mirror"
+
+	// GreptimeCNSourceProvider provides the artifacts from the S3 bucket of the CN region if the CN region is required.
+	GreptimeCNSourceProvider = "greptime-cn"
+
+	// GitHubSourceProvider provides the binaries and GreptimeDB charts from the GitHub releases.
+	GitHubSourceProvider = "github"
+
+	// OCISourceProvider provides the charts from the OCI registries.
+	OCISourceProvider = "oci"
+
+	// SourceOrderEnv is the environment variable of the resolution order of source providers, for example, 'local,github,oci'.
+	SourceOrderEnv = "GTCTL_SOURCE_ORDER"
+)
+
+// DefaultSourceOrder is the default resolution order of source providers.
+var DefaultSourceOrder = []string{
+	LocalSourceProvider,
+	MirrorSourceProvider,
+	GreptimeCNSourceProvider,
+	GitHubSourceProvider,
+	OCISourceProvider,
+}
+
+// SourceProvider provides the download location of artifacts.
+// The providers are asked in the resolution order, and the first one that provides the artifact is used.
+type SourceProvider interface {
+	// Name returns the unique name of the provider, which is used in the resolution order.
+	Name() string
+
+	// Provide fills the FileName, URL and ChecksumURL of the src whose Name, Type, Version and Platform are already set.
+	// It returns false if the provider doesn't provide the artifact.
+	Provide(src *Source) (bool, error)
+}
+
+// LatestVersionProvider is the optional interface of SourceProvider, which resolves the latest version of the artifacts it provides.
+// It's useful for the artifacts that gtctl doesn't know how to resolve the latest version.
+type LatestVersionProvider interface {
+	// LatestVersion returns the latest version of the artifact, or false if the provider doesn't provide the artifact.
+	LatestVersion(ctx context.Context, typ ArtifactType, name string, fromCNRegion bool) (string, bool, error)
+}
+
+// Platform is the target platform of binaries.
+type Platform struct {
+	// OS is the operating system, for example, 'linux' and 'darwin'.
+	OS string
+
+	// Arch is the architecture, for example, 'amd64' and 'arm64'.
+	Arch string
+}
+
+// WithSourceProviders registers the source providers, the provider that has the same name as the registered one replaces it.
+// The providers that are not in the resolution order are asked before the ones in it.
+func WithSourceProviders(providers ...SourceProvider) Option {
+	return func(m *manager) {
+		m.customProviders = append(m.customProviders, providers...)
+	}
+}
+
+// WithSourceOrder sets the resolution order of source providers, it overrides the order from config file and environment variable.
+func WithSourceOrder(names ...string) Option {
+	return func(m *manager) {
+		m.sourceOrder = names
+	}
+}
+
+// newSourceProviders returns the source providers in the resolution order.
+func (m *manager) newSourceProviders() ([]SourceProvider, error) {
+	registered := map[string]SourceProvider{
+		MirrorSourceProvider:     &mirrorProvider{mirrors: m.mirrors},
+		GreptimeCNSourceProvider: newBucketProvider(GreptimeCNSourceProvider, GreptimeReleaseBucketCN, true),
+		GitHubSourceProvider:     &githubProvider{},
+		OCISourceProvider:        &ociProvider{},
+	}
+	if len(m.mirrors.LocalDir) > 0 {
+		registered[LocalSourceProvider] = &localProvider{
+			bucket: newBucketProvider(LocalSourceProvider, (&url.URL{Scheme: "file", Path: m.mirrors.LocalDir}).String(), false),
+		}
+	}
+
+	order := m.sourceOrder
+	if len(order) == 0 {
+		order = m.mirrors.SourceOrder
+	}
+	if len(order) == 0 {
+		order = DefaultSourceOrder
+	}
+
+	var providers []SourceProvider
+	for _, p := range m.customProviders {
+		if _, ok := registered[p.Name()]; ok {
+			// Replace the built-in provider in place.
+			registered[p.Name()] = p
+			continue
+		}
+		if !contains(order, p.Name()) {
+			providers = append(providers, p)
+		}
+		registered[p.Name()] = p
+	}
+
+	for _, name := range order {
+		p, ok := registered[name]
+		if !ok {
+			// The local provider is only available when the local directory is configured.
+			if name == LocalSourceProvider {
+				continue
+			}
+			return nil, fmt.Errorf("unknown source provider '%s'", name)
+		}
+		providers = append(providers, p)
+	}
+
+	return providers, nil
+}
+
+// bucketProvider provides the artifacts from the S3-compatible bucket or HTTP directory that has the layout of 'downloads.greptime.cn':
+//   - '<base>/charts/<chart>/<version>/<chart>-<version>.tgz';
+//   - '<base>/greptimedb/<version>/<package>';
+//   - '<base>/etcd/<version>/<package>'.
+type bucketProvider struct {
+	name string
+
+	// The base URLs of charts and binaries.
+	charts           string
+	greptimeBinaries string
+	etcdBinaries     string
+
+	// If cnRegionOnly is true, only the artifacts from the CN region are provided.
+	cnRegionOnly bool
+}
+
+var _ SourceProvider = &bucketProvider{}
+
+func newBucketProvider(name, baseURL string, cnRegionOnly bool) *bucketProvider {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &bucketProvider{
+		name:             name,
+		charts:           baseURL + "/charts",
+		greptimeBinaries: baseURL + "/greptimedb",
+		etcdBinaries:     baseURL + "/etcd",
+		cnRegionOnly:     cnRegionOnly,
+	}
+}
+
+func (p *bucketProvider) Name() string {
+	return p.name
+}
+
+func (p *bucketProvider) Provide(src *Source) (bool, error) {
+	if p.cnRegionOnly && !src.FromCNRegion {
+		return false, nil
+	}
+
+	switch {
+	case src.Type == ArtifactTypeChart && len(p.charts) > 0:
+		// The download URL example: 'https://downloads.greptime.cn/releases/charts/etcd/9.2.0/etcd-9.2.0.tgz'.
+		src.FileName = chartFileName(src.Name, src.Version)
+		src.URL = fmt.Sprintf("%s/%s/%s/%s", strings.TrimSuffix(p.charts, "/"), src.Name, src.Version, src.FileName)
+		return true, nil
+	case src.Type == ArtifactTypeBinary && src.Name == GreptimeBinName && len(p.greptimeBinaries) > 0:
+		return true, provideBinary(src, p.greptimeBinaries)
+	case src.Type == ArtifactTypeBinary && src.Name == EtcdBinName && len(p.etcdBinaries) > 0:
+		return true, provideBinary(src, p.etcdBinaries)
+	default:
+		return false, nil
+	}
+}
+
+// mirrorProvider provides the artifacts from the mirrors, which are the HTTP directories except the etcd chart registry.
+type mirrorProvider struct {
+	mirrors *Mirrors
+}
+
+var _ SourceProvider = &mirrorProvider{}
+
+func (p *mirrorProvider) Name() string {
+	return MirrorSourceProvider
+}
+
+func (p *mirrorProvider) Provide(src *Source) (bool, error) {
+	if src.Type == ArtifactTypeChart && src.Name == EtcdChartName && len(p.mirrors.EtcdChartRegistry) > 0 {
+		src.FileName = chartFileName(src.Name, src.Version)
+		src.URL = p.mirrors.EtcdChartRegistry
+		return true, nil
+	}
+
+	// The mirrors have the same layout as the artifacts in CN region.
+	bucket := &bucketProvider{
+		name:             MirrorSourceProvider,
+		charts:           p.mirrors.Charts,
+		greptimeBinaries: p.mirrors.GreptimeBinaries,
+		etcdBinaries:     p.mirrors.EtcdBinaries,
+	}
+	return bucket.Provide(src)
+}
+
+// localProvider provides the artifacts that exist in the local directory, which has the same layout as 'downloads.greptime.cn'.
+type localProvider struct {
+	bucket *bucketProvider
+}
+
+var _ SourceProvider = &localProvider{}
+
+func (p *localProvider) Name() string {
+	return LocalSourceProvider
+}
+
+func (p *localProvider) Provide(src *Source) (bool, error) {
+	candidate := *src
+	ok, err := p.bucket.Provide(&candidate)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	u, err := url.Parse(candidate.URL)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(filepath.FromSlash(u.Path)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	*src = candidate
+	return true, nil
+}
+
+// githubProvider provides the binaries and GreptimeDB charts from the GitHub releases.
+type githubProvider struct{}
+
+var _ SourceProvider = &githubProvider{}
+
+func (p *githubProvider) Name() string {
+	return GitHubSourceProvider
+}
+
+func (p *githubProvider) Provide(src *Source) (bool, error) {
+	switch {
+	case src.Type == ArtifactTypeChart && src.Name != EtcdChartName:
+		// The download URL example: 'https://github.com/GreptimeTeam/helm-charts/releases/download/greptimedb-0.1.1-alpha.3/greptimedb-0.1.1-alpha.3.tgz'.
+		src.FileName = chartFileName(src.Name, src.Version)
+		src.URL = fmt.Sprintf("%s/%s/%s", GreptimeChartReleaseDownloadURL, strings.TrimSuffix(src.FileName, fileutils.TgzExtension), src.FileName)
+		return true, nil
+	case src.Type == ArtifactTypeBinary && src.Name == GreptimeBinName:
+		return true, provideBinary(src, fmt.Sprintf("https://github.com/%s/%s/releases/download", GreptimeGitHubOrg, GreptimeDBGithubRepo))
+	case src.Type == ArtifactTypeBinary && src.Name == EtcdBinName:
+		return true, provideBinary(src, fmt.Sprintf("https://github.com/%s/%s/releases/download", EtcdGitHubOrg, EtcdGithubRepo))
+	default:
+		return false, nil
+	}
+}
+
+// ociProvider provides the charts from the OCI registries.
+type ociProvider struct{}
+
+var _ SourceProvider = &ociProvider{}
+
+func (p *ociProvider) Name() string {
+	return OCISourceProvider
+}
+
+func (p *ociProvider) Provide(src *Source) (bool, error) {
+	if src.Type == ArtifactTypeChart && src.Name == EtcdChartName {
+		// The download URL example: 'oci://registry-1.docker.io/bitnamicharts/etcd:9.2.0'.
+		src.FileName = chartFileName(src.Name, src.Version)
+		src.URL = EtcdOCIRegistry
+		return true, nil
+	}
+	return false, nil
+}
+
+// provideBinary fills the source of greptime or etcd binary whose releases are in '<baseURL>/<version>/'.
+func provideBinary(src *Source, baseURL string) error {
+	packageName, checksumName, err := packageNames(src.Name, src.Version, src.Platform)
+	if err != nil {
+		return err
+	}
+
+	releaseURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(baseURL, "/"), src.Version)
+	src.URL = fmt.Sprintf("%s/%s", releaseURL, packageName)
+	src.FileName = packageName
+	src.ChecksumURL = fmt.Sprintf("%s/%s", releaseURL, checksumName)
+
+	return nil
+}
+
+func chartFileName(chartName, version string) string {
+	return fmt.Sprintf("%s-%s.tgz", chartName, version)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+// vectorProvider provides the vector binary, which gtctl doesn't know.
+type vectorProvider struct{}
+
+func (p *vectorProvider) Name() string {
+	return "vector"
+}
+
+func (p *vectorProvider) Provide(src *Source) (bool, error) {
+	if src.Name != "vector" || src.Type != ArtifactTypeBinary {
+		return false, nil
+	}
+	src.FileName = fmt.Sprintf("vector-%s-%s-%s.tar.gz", src.Version, src.Platform.OS, src.Platform.Arch)
+	src.URL = fmt.Sprintf("https://packages.example.com/vector/%s/%s", src.Version, src.FileName)
+	return true, nil
+}
+
+func (p *vectorProvider) LatestVersion(_ context.Context, _ ArtifactType, name string, _ bool) (string, bool, error) {
+	return "0.33.0", name == "vector", nil
+}
+
+func TestSourceProviders(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Only greptime v0.4.0 exists in the local directory.
+	localDir := filepath.Join(tempDir, "local")
+	localFile := filepath.Join(localDir, "greptimedb", "v0.4.0", "greptime-linux-amd64-v0.4.0.tar.gz")
+	if err := os.MkdirAll(filepath.Dir(localFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(localFile, []byte("greptime"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithMirrors(&Mirrors{LocalDir: localDir}),
+		WithPlatform("linux", "amd64"),
+		WithCacheDir(filepath.Join(tempDir, "cache")),
+		WithSourceProviders(&vectorProvider{}))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		version      string
+		typ          ArtifactType
+		fromCNRegion bool
+		url          string
+		checksumURL  string
+	}{
+		{GreptimeBinName, "v0.4.0", ArtifactTypeBinary, false,
+			"file://" + localFile, "file://" + filepath.Dir(localFile) + "/greptime-linux-amd64-v0.4.0.sha256sum"},
+		{GreptimeBinName, "v0.4.1", ArtifactTypeBinary, false,
+			"https://github.com/GreptimeTeam/greptimedb/releases/download/v0.4.1/greptime-linux-amd64-v0.4.1.tar.gz",
+			"https://github.com/GreptimeTeam/greptimedb/releases/download/v0.4.1/greptime-linux-amd64-v0.4.1.sha256sum"},
+		{EtcdBinName, DefaultEtcdBinVersion, ArtifactTypeBinary, true,
+			"https://downloads.greptime.cn/releases/etcd/v3.5.7/etcd-v3.5.7-linux-amd64.tar.gz",
+			"https://downloads.greptime.cn/releases/etcd/v3.5.7/SHA256SUMS"},
+		{EtcdChartName, DefaultEtcdChartVersion, ArtifactTypeChart, false, EtcdOCIRegistry, ""},
+		{GreptimeDBClusterChartName, "0.1.2", ArtifactTypeChart, false,
+			"https://github.com/GreptimeTeam/helm-charts/releases/download/greptimedb-cluster-0.1.2/greptimedb-cluster-0.1.2.tgz", ""},
+		{"vector", LatestVersionTag, ArtifactTypeBinary, false,
+			"https://packages.example.com/vector/0.33.0/vector-0.33.0-linux-amd64.tar.gz", ""},
+	}
+	for _, tt := range tests {
+		src, err := m.NewSource(tt.name, tt.version, tt.typ, tt.fromCNRegion)
+		if err != nil {
+			t.Errorf("failed to create source of '%s': %v", tt.name, err)
+			continue
+		}
+		if src.URL != tt.url {
+			t.Errorf("expected URL '%s', got '%s'", tt.url, src.URL)
+		}
+		if src.ChecksumURL != tt.checksumURL {
+			t.Errorf("expected checksum URL '%s', got '%s'", tt.checksumURL, src.ChecksumURL)
+		}
+	}
+
+	if _, err := m.NewSource("flownode", "v0.1.0", ArtifactTypeBinary, false); err == nil {
+		t.Errorf("expected error for the artifact without provider")
+	}
+
+	// The binary for another platform is not in the local directory.
+	src, err := m.NewSource(GreptimeBinName, "v0.4.0", ArtifactTypeBinary, false,
+		WithSourcePlatform(Platform{OS: "linux", Arch: "arm64"}))
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	expected := "https://github.com/GreptimeTeam/greptimedb/releases/download/v0.4.0/greptime-linux-arm64-v0.4.0.tar.gz"
+	if src.URL != expected || src.Platform.Arch != "arm64" {
+		t.Errorf("expected URL '%s' for arm64, got '%s'", expected, src.URL)
+	}
+}
+
+func TestSourceOrder(t *testing.T) {
+	// The GitHub releases are preferred even if the artifacts from CN region are required.
+	m, err := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithMirrors(&Mirrors{SourceOrder: []string{GitHubSourceProvider, GreptimeCNSourceProvider}}),
+		WithPlatform("linux", "amd64"))
+	if err != nil {
+		t.Fatalf("failed to create artifacts manager: %v", err)
+	}
+
+	src, err := m.NewSource(GreptimeBinName, "v0.4.1", ArtifactTypeBinary, true)
+	if err != nil {
+		t.Fatalf("failed to create source: %v", err)
+	}
+	expected := "https://github.com/GreptimeTeam/greptimedb/releases/download/v0.4.1/greptime-linux-amd64-v0.4.1.tar.gz"
+	if src.URL != expected {
+		t.Errorf("expected URL '%s', got '%s'", expected, src.URL)
+	}
+
+	// The etcd chart is only provided by the OCI provider which is not in the order.
+	if _, err := m.NewSource(EtcdChartName, DefaultEtcdChartVersion, ArtifactTypeChart, false); err == nil {
+		t.Errorf("expected error for the artifact without provider")
+	}
+
+	// The option overrides the order in mirror config.
+	_, err = NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()),
+		WithMirrors(&Mirrors{}), WithSourceOrder("unknown"))
+	if err == nil {
+		t.Errorf("expected error for unknown source provider")
+	}
+}
@@ -0,0 +1,345 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// ManifestFile is the file name of the manifest in bundle.
+	ManifestFile = "manifest.yaml"
+
+	// ManifestAPIVersion is the current version of the bundle manifest.
+	ManifestAPIVersion = "gtctl.greptime.io/v1alpha1"
+
+	chartsDir   = "charts"
+	binariesDir = "binaries"
+)
+
+// Manifest describes all the artifacts in a bundle.
+type Manifest struct {
+	APIVersion string      `yaml:"apiVersion"`
+	CreatedAt  time.Time   `yaml:"createdAt"`
+	Artifacts  []*Artifact `yaml:"artifacts"`
+}
+
+// Artifact is an artifact in bundle.
+type Artifact struct {
+	Name    string                 `yaml:"name"`
+	Type    artifacts.ArtifactType `yaml:"type"`
+	Version string                 `yaml:"version"`
+
+	// OS and Arch are the target platform of binary, they are empty for charts.
+	OS   string `yaml:"os,omitempty"`
+	Arch string `yaml:"arch,omitempty"`
+
+	// File is the path of the artifact in bundle.
+	File string `yaml:"file"`
+
+	// SHA256 is the checksum of the artifact file.
+	SHA256 string `yaml:"sha256"`
+}
+
+// Chart is the chart to bundle, the empty version means the latest version.
+type Chart struct {
+	Name    string
+	Version string
+}
+
+// Platform is the target platform of binaries.
+type Platform = artifacts.Platform
+
+// Options is the options to create a bundle.
+type Options struct {
+	// GreptimeVersion is the version of greptime binary, it will be skipped if it's empty.
+	GreptimeVersion string
+
+	// EtcdVersion is the version of etcd binary, it will be skipped if it's empty.
+	EtcdVersion string
+
+	// Charts is the charts to bundle.
+	Charts []Chart
+
+	// Platforms is the target platforms of binaries.
+	Platforms []Platform
+
+	// FromCNRegion indicates whether to download the artifacts from CN region.
+	FromCNRegion bool
+
+	// Output is the path of the bundle file.
+	Output string
+
+	// ArtifactsOptions is the extra options of artifacts manager, such as mirrors.
+	ArtifactsOptions []artifacts.Option
+}
+
+// Create downloads the artifacts and packs them with the manifest into a '.tar.gz' bundle.
+func Create(ctx context.Context, l logger.Logger, opts *Options) (*Manifest, error) {
+	tempDir, err := os.MkdirTemp("", "gtctl-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	manifest := &Manifest{
+		APIVersion: ManifestAPIVersion,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	am, err := artifacts.NewManager(l, opts.ArtifactsOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	add := func(name, version string, typ artifacts.ArtifactType, platform *Platform) error {
+		var sourceOpts []artifacts.SourceOption
+		if platform != nil {
+			sourceOpts = append(sourceOpts, artifacts.WithSourcePlatform(*platform))
+		}
+		src, err := am.NewSource(name, version, typ, opts.FromCNRegion, sourceOpts...)
+		if err != nil {
+			return err
+		}
+
+		artifact := &Artifact{Name: src.Name, Type: src.Type, Version: src.Version}
+		if typ == artifacts.ArtifactTypeChart {
+			artifact.File = path.Join(chartsDir, src.Name, src.Version, src.FileName)
+		} else {
+			artifact.OS, artifact.Arch = src.Platform.OS, src.Platform.Arch
+			artifact.File = path.Join(binariesDir, fmt.Sprintf("%s-%s", artifact.OS, artifact.Arch), src.Name, src.Version, src.FileName)
+		}
+
+		l.V(0).Infof("Downloading %s '%s' version '%s'...", typ, src.Name, src.Version)
+		destDir := filepath.Join(tempDir, filepath.Dir(filepath.FromSlash(artifact.File)))
+		artifactFile, err := am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{SkipInstall: true})
+		if err != nil {
+			return fmt.Errorf("failed to download %s '%s': %v", typ, src.Name, err)
+		}
+
+		if artifact.SHA256, err = artifacts.FileSHA256(artifactFile); err != nil {
+			return err
+		}
+		manifest.Artifacts = append(manifest.Artifacts, artifact)
+
+		return nil
+	}
+
+	for _, chart := range opts.Charts {
+		if err := add(chart.Name, chart.Version, artifacts.ArtifactTypeChart, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range opts.Platforms {
+		platform := &opts.Platforms[i]
+		if len(opts.GreptimeVersion) > 0 {
+			if err := add(artifacts.GreptimeBinName, opts.GreptimeVersion, artifacts.ArtifactTypeBinary, platform); err != nil {
+				return nil, err
+			}
+		}
+		if len(opts.EtcdVersion) > 0 {
+			if err := add(artifacts.EtcdBinName, opts.EtcdVersion, artifacts.ArtifactTypeBinary, platform); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(manifest.Artifacts) == 0 {
+		return nil, fmt.Errorf("no artifacts to bundle")
+	}
+
+	if err := writeBundle(opts.Output, tempDir, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Import extracts the bundle and places the artifacts in the layout of metadata manager, so that they can be used as cache.
+// Only the binaries of the given platform will be imported because the layout of binaries is platform independent.
+func Import(l logger.Logger, bundleFile string, mm metadata.Manager, platform Platform) (*Manifest, error) {
+	if err := fileutils.EnsureDir(mm.GetWorkingDir()); err != nil {
+		return nil, err
+	}
+
+	// Extract the bundle in the working directory, so that the artifacts can be renamed to the destination.
+	tempDir, err := os.MkdirTemp(mm.GetWorkingDir(), "bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The files in bundle are extracted with the same checks as the packages of binaries,
+	// so that the files outside the tempDir can't be written.
+	if !fileutils.IsArchive(bundleFile) {
+		return nil, fmt.Errorf("invalid bundle '%s', it should be a '.tar.gz' file", bundleFile)
+	}
+	if err := fileutils.Uncompress(bundleFile, tempDir); err != nil {
+		return nil, fmt.Errorf("invalid bundle '%s': %v", bundleFile, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle '%s': %v", bundleFile, err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest of bundle '%s': %v", bundleFile, err)
+	}
+	if manifest.APIVersion != ManifestAPIVersion {
+		return nil, fmt.Errorf("unsupported bundle apiVersion '%s', expected '%s'", manifest.APIVersion, ManifestAPIVersion)
+	}
+
+	imported := &Manifest{APIVersion: manifest.APIVersion, CreatedAt: manifest.CreatedAt}
+	for _, artifact := range manifest.Artifacts {
+		if artifact.Type == artifacts.ArtifactTypeBinary && (artifact.OS != platform.OS || artifact.Arch != platform.Arch) {
+			l.V(3).Infof("Skip importing %s '%s' for platform %s/%s", artifact.Type, artifact.Name, artifact.OS, artifact.Arch)
+			continue
+		}
+
+		file := filepath.Join(tempDir, filepath.FromSlash(artifact.File))
+		checksum, err := artifacts.FileSHA256(file)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != artifact.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch of '%s' in bundle, expected '%s', got '%s'", artifact.File, artifact.SHA256, checksum)
+		}
+
+		src := &artifacts.Source{
+			Name:     artifact.Name,
+			Type:     artifact.Type,
+			Version:  artifact.Version,
+			FileName: path.Base(artifact.File),
+		}
+		destDir, err := mm.AllocateArtifactFilePath(src, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := fileutils.EnsureDir(destDir); err != nil {
+			return nil, err
+		}
+
+		dest := filepath.Join(destDir, src.FileName)
+		if err := os.Rename(file, dest); err != nil {
+			return nil, err
+		}
+
+		// Record the checksum so that the artifact can be used as a valid cache.
+		if err := os.WriteFile(dest+artifacts.ChecksumFileSuffix, []byte(checksum+"\n"), 0644); err != nil {
+			return nil, err
+		}
+
+		l.V(0).Infof("Imported %s '%s' version '%s'", artifact.Type, artifact.Name, artifact.Version)
+		imported.Artifacts = append(imported.Artifacts, artifact)
+	}
+
+	return imported, nil
+}
+
+// writeBundle writes the manifest and the artifacts in dir to the '.tar.gz' file.
+func writeBundle(output, dir string, manifest *Manifest) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	// Write to the temporary file first, so that the incomplete bundle will not be left.
+	tempFile := output + ".tmp"
+	f, err := os.Create(tempFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    ManifestFile,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: manifest.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, artifact := range manifest.Artifacts {
+		if err := addFile(tw, filepath.Join(dir, filepath.FromSlash(artifact.File)), artifact.File); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile, output)
+}
+
+func addFile(tw *tar.Writer, file, name string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestCreateAndImport(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Prepare the mirror that has the same layout as 'downloads.greptime.cn'.
+	mirrorDir := filepath.Join(tempDir, "mirror")
+	files := map[string]string{
+		"charts/greptimedb-cluster/latest-version.txt":                 "0.1.2\n",
+		"charts/greptimedb-cluster/0.1.2/greptimedb-cluster-0.1.2.tgz": "chart",
+		"etcd/v3.5.7/etcd-v3.5.7-linux-amd64.tar.gz":                   "etcd-amd64",
+		"etcd/v3.5.7/etcd-v3.5.7-linux-arm64.tar.gz":                   "etcd-arm64",
+	}
+	for name, content := range files {
+		file := filepath.Join(mirrorDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := logger.New(os.Stdout, log.Level(4), logger.WithColored())
+	output := filepath.Join(tempDir, "bundle.tar.gz")
+	manifest, err := Create(context.Background(), l, &Options{
+		EtcdVersion: artifacts.DefaultEtcdBinVersion,
+		Charts:      []Chart{{Name: artifacts.GreptimeDBClusterChartName}},
+		Platforms:   []Platform{{OS: "linux", Arch: "amd64"}, {OS: "linux", Arch: "arm64"}},
+		Output:      output,
+		ArtifactsOptions: []artifacts.Option{artifacts.WithMirrors(&artifacts.Mirrors{
+			Charts:       "file://" + filepath.Join(mirrorDir, "charts"),
+			EtcdBinaries: "file://" + filepath.Join(mirrorDir, "etcd"),
+		})},
+	})
+	if err != nil {
+		t.Fatalf("failed to create bundle: %v", err)
+	}
+	if len(manifest.Artifacts) != 3 {
+		t.Fatalf("expected 3 artifacts in bundle, got %d", len(manifest.Artifacts))
+	}
+	if manifest.Artifacts[0].Version != "0.1.2" {
+		t.Errorf("expected the resolved chart version 0.1.2, got %s", manifest.Artifacts[0].Version)
+	}
+
+	mm, err := metadata.New(filepath.Join(tempDir, "home"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := Import(l, output, mm, Platform{OS: "linux", Arch: "amd64"})
+	if err != nil {
+		t.Fatalf("failed to import bundle: %v", err)
+	}
+	if len(imported.Artifacts) != 2 {
+		t.Fatalf("expected 2 imported artifacts, got %d", len(imported.Artifacts))
+	}
+
+	tests := []struct {
+		src     *artifacts.Source
+		content string
+	}{
+		{
+			src: &artifacts.Source{Name: artifacts.GreptimeDBClusterChartName, Type: artifacts.ArtifactTypeChart,
+				Version: "0.1.2", FileName: "greptimedb-cluster-0.1.2.tgz"},
+			content: "chart",
+		},
+		{
+			src: &artifacts.Source{Name: artifacts.EtcdBinName, Type: artifacts.ArtifactTypeBinary,
+				Version: artifacts.DefaultEtcdBinVersion, FileName: "etcd-v3.5.7-linux-amd64.tar.gz"},
+			content: "etcd-amd64",
+		},
+	}
+	for _, tt := range tests {
+		dir, err := mm.AllocateArtifactFilePath(tt.src, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		file := filepath.Join(dir, tt.src.FileName)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read imported artifact: %v", err)
+		}
+		if string(data) != tt.content {
+			t.Errorf("unexpected content of '%s': %s", file, string(data))
+		}
+		if _, err := os.Stat(file + artifacts.ChecksumFileSuffix); err != nil {
+			t.Errorf("checksum file of '%s' is not created: %v", file, err)
+		}
+	}
+}
+
+func TestImportRejectsUnsafePath(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	output := filepath.Join(tempDir, "bundle.tar.gz")
+	f, err := os.Create(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	content := []byte("evil")
+	if err := tw.WriteHeader(&tar.Header{Name: "charts/../../evil.tgz", Mode: 0644, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mm, err := metadata.New(filepath.Join(tempDir, "home"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(logger.New(os.Stdout, log.Level(4), logger.WithColored()), output, mm, Platform{}); err == nil {
+		t.Error("expected error when importing the file outside of the bundle")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "evil.tgz")); !os.IsNotExist(err) {
+		t.Errorf("the file outside of the bundle should not be created")
+	}
+}
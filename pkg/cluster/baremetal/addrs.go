@@ -0,0 +1,190 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+const (
+	// wildcardHost is the host that the address is replaced with if its host is not available on this machine.
+	wildcardHost = "0.0.0.0"
+
+	// maxPortScan is the number of ports after the unavailable one that are scanned for the replacement.
+	maxPortScan = 1000
+)
+
+// etcdPorts are the ports that etcd listens on, which is started with the default addresses.
+var etcdPorts = []int{2379, 2380}
+
+// listenAddr is a listen address in the config of cluster, the replicas listen on the consecutive ports from it.
+type listenAddr struct {
+	name     string
+	addr     *string
+	replicas int
+}
+
+// ReplaceUnavailableAddrs replaces the listen addresses in the config that are used by other processes, conflict with
+// each other or whose hosts are not on this machine, with the available ones. The replacements are returned as
+// 'name: old -> new' messages. The server address of meta srv follows its bind address, since it's the address that
+// frontend and datanodes connect to.
+func ReplaceUnavailableAddrs(cfg *config.BareMetalClusterConfig) ([]string, error) {
+	if cfg == nil || cfg.Cluster == nil || cfg.Cluster.Frontend == nil ||
+		cfg.Cluster.MetaSrv == nil || cfg.Cluster.Datanode == nil {
+		return nil, fmt.Errorf("missing the config of cluster components")
+	}
+
+	var (
+		frontend = cfg.Cluster.Frontend
+		metaSrv  = cfg.Cluster.MetaSrv
+		datanode = cfg.Cluster.Datanode
+	)
+
+	bindAddr := metaSrv.BindAddr
+	if len(bindAddr) == 0 {
+		bindAddr = components.DefaultMetaSrvBindAddr
+	}
+	originalBindAddr := bindAddr
+	addrs := []listenAddr{
+		{name: "frontend.httpAddr", addr: &frontend.HTTPAddr, replicas: frontend.Replicas},
+		{name: "frontend.grpcAddr", addr: &frontend.GRPCAddr, replicas: frontend.Replicas},
+		{name: "frontend.mysqlAddr", addr: &frontend.MysqlAddr, replicas: frontend.Replicas},
+		{name: "frontend.postgresAddr", addr: &frontend.PostgresAddr, replicas: frontend.Replicas},
+		{name: "frontend.opentsdbAddr", addr: &frontend.OpentsdbAddr, replicas: frontend.Replicas},
+		{name: "meta.bindAddr", addr: &bindAddr, replicas: metaSrv.Replicas},
+		{name: "meta.httpAddr", addr: &metaSrv.HTTPAddr, replicas: metaSrv.Replicas},
+		{name: "datanode.rpcAddr", addr: &datanode.RPCAddr, replicas: datanode.Replicas},
+		{name: "datanode.httpAddr", addr: &datanode.HTTPAddr, replicas: datanode.Replicas},
+	}
+
+	claimed := make(map[int]bool)
+	for _, port := range etcdPorts {
+		claimed[port] = true
+	}
+
+	var replacements []string
+	for _, a := range addrs {
+		if len(*a.addr) == 0 {
+			continue
+		}
+
+		host, port, err := splitAddr(*a.addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address '%s' of %s: %v", *a.addr, a.name, err)
+		}
+		replicas := a.replicas
+		if replicas < 1 {
+			replicas = 1
+		}
+
+		newHost, newPort, ok := host, port, portsAvailable(host, port, replicas, claimed)
+		if !ok {
+			newPort, ok = findAvailablePorts(host, port+1, replicas, claimed)
+		}
+		if !ok && host != wildcardHost {
+			newHost = wildcardHost
+			newPort, ok = findAvailablePorts(newHost, port, replicas, claimed)
+		}
+		if !ok {
+			return nil, fmt.Errorf("no available ports for %s '%s'", a.name, *a.addr)
+		}
+
+		for i := 0; i < replicas; i++ {
+			claimed[newPort+i] = true
+		}
+		if newHost == host && newPort == port {
+			continue
+		}
+
+		newAddr := net.JoinHostPort(newHost, strconv.Itoa(newPort))
+		replacements = append(replacements, fmt.Sprintf("%s: %s -> %s", a.name, *a.addr, newAddr))
+		*a.addr = newAddr
+	}
+
+	if bindAddr != originalBindAddr {
+		metaSrv.BindAddr = bindAddr
+		serverAddr, err := advertisedAddr(metaSrv.ServerAddr, bindAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address '%s' of meta.serverAddr: %v", metaSrv.ServerAddr, err)
+		}
+		if serverAddr != metaSrv.ServerAddr {
+			replacements = append(replacements, fmt.Sprintf("meta.serverAddr: %s -> %s", metaSrv.ServerAddr, serverAddr))
+			metaSrv.ServerAddr = serverAddr
+		}
+	}
+
+	return replacements, nil
+}
+
+// advertisedAddr returns the address that has the port of bind address, the host is kept unless the bind address
+// is replaced with the wildcard host, which means the original host is not on this machine.
+func advertisedAddr(addr, bindAddr string) (string, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	bindHost, bindPort, err := net.SplitHostPort(bindAddr)
+	if err != nil {
+		return "", err
+	}
+	if bindHost == wildcardHost {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, bindPort), nil
+}
+
+func splitAddr(addr string) (string, int, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	portInt, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, portInt, nil
+}
+
+// findAvailablePorts returns the first port from start that the replicas can listen on consecutively.
+func findAvailablePorts(host string, start, replicas int, claimed map[int]bool) (int, bool) {
+	for port := start; port < start+maxPortScan && port+replicas-1 <= 65535; port++ {
+		if portsAvailable(host, port, replicas, claimed) {
+			return port, true
+		}
+	}
+	return 0, false
+}
+
+// portsAvailable returns true if the consecutive ports from port are not claimed and can be listened on.
+func portsAvailable(host string, port, replicas int, claimed map[int]bool) bool {
+	for i := 0; i < replicas; i++ {
+		if claimed[port+i] {
+			return false
+		}
+		l, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port+i)))
+		if err != nil {
+			return false
+		}
+		l.Close()
+	}
+	return true
+}
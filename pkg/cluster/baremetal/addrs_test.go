@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+)
+
+func TestReplaceUnavailableAddrs(t *testing.T) {
+	// Occupy a port, and use the port after it as the conflict one.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+	busyAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.Datanode.Replicas = 1
+	cfg.Cluster.Datanode.RPCAddr = busyAddr
+	cfg.Cluster.MetaSrv.BindAddr = busyAddr
+	cfg.Cluster.MetaSrv.ServerAddr = busyAddr
+	cfg.Cluster.Frontend.HTTPAddr = "192.0.2.1:" + strconv.Itoa(port+10)
+
+	replacements, err := ReplaceUnavailableAddrs(cfg)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, replacements)
+
+	// The bind address of meta srv takes the next available port, and the server address follows it.
+	assert.NotEqual(t, busyAddr, cfg.Cluster.MetaSrv.BindAddr)
+	assert.Equal(t, cfg.Cluster.MetaSrv.BindAddr, cfg.Cluster.MetaSrv.ServerAddr)
+
+	// The datanode doesn't take the port of meta srv.
+	assert.NotEqual(t, busyAddr, cfg.Cluster.Datanode.RPCAddr)
+	assert.NotEqual(t, cfg.Cluster.MetaSrv.BindAddr, cfg.Cluster.Datanode.RPCAddr)
+
+	// The host that is not on this machine is replaced with the wildcard host.
+	host, _, err := net.SplitHostPort(cfg.Cluster.Frontend.HTTPAddr)
+	assert.NoError(t, err)
+	assert.Equal(t, wildcardHost, host)
+
+	// The replaced config is available.
+	replacements, err = ReplaceUnavailableAddrs(cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, replacements)
+}
@@ -0,0 +1,332 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// BackupManifestFile is the file name of the manifest in backup.
+	BackupManifestFile = "manifest.yaml"
+
+	// BackupAPIVersion is the current version of the backup manifest.
+	BackupAPIVersion = "gtctl.greptime.io/v1alpha1"
+
+	// backupDataDir is the directory in backup that contains the data directory of cluster,
+	// such as the data of etcd and the home and wal directories of each datanode.
+	backupDataDir = "data"
+)
+
+// BackupManifest describes the cluster in a backup.
+type BackupManifest struct {
+	APIVersion string    `yaml:"apiVersion"`
+	CreatedAt  time.Time `yaml:"createdAt"`
+
+	// Name is the name of the cluster that is backed up.
+	Name string `yaml:"name"`
+
+	// Config is the config of the cluster, the versions of artifacts in it are resolved when the cluster was created.
+	Config *config.BareMetalClusterConfig `yaml:"config"`
+}
+
+// IsBackupFile returns true if the file has the extension of backup, that is '.tar.gz' or '.tgz'.
+func IsBackupFile(file string) bool {
+	return strings.HasSuffix(file, fileutils.TarGzExtension) || strings.HasSuffix(file, fileutils.TgzExtension)
+}
+
+// Backup archives the data directory and the config of a stopped cluster into a '.tar.gz' file.
+// The cluster should be stopped before backing up, so that the data of etcd and datanodes are consistent.
+func (c *Cluster) Backup(ctx context.Context, options *opt.BackupOptions) error {
+	if !IsBackupFile(options.Output) {
+		return fmt.Errorf("invalid backup file '%s', it should be a '%s' file", options.Output, fileutils.TarGzExtension)
+	}
+
+	cluster, err := c.get(ctx, &opt.GetOptions{Name: options.Name})
+	if err != nil {
+		return err
+	}
+
+	running, ferr, serr := c.isClusterRunning(cluster.ForegroundPid)
+	if ferr != nil {
+		return fmt.Errorf("error checking whether cluster '%s' is running: %v", options.Name, ferr)
+	}
+	if running || serr == nil {
+		return fmt.Errorf("cluster '%s' is running, please stop it before backing up", options.Name)
+	}
+
+	// The replicas may outlive the foreground process, e.g. it's killed by SIGKILL.
+	csd := c.mm.GetClusterScopeDirs()
+	records := collectRuntimeRecordsForBareMetal(csd.PidsDir)
+	replicas := make([]string, 0, len(records))
+	for replica := range records {
+		replicas = append(replicas, replica)
+	}
+	sort.Strings(replicas)
+	for _, replica := range replicas {
+		if records[replica].IsAlive() {
+			return fmt.Errorf("'%s' of cluster '%s' is still running with pid %d, please stop it before backing up",
+				replica, options.Name, records[replica].Pid)
+		}
+	}
+
+	manifest := &BackupManifest{
+		APIVersion: BackupAPIVersion,
+		CreatedAt:  time.Now(),
+		Name:       options.Name,
+		Config:     cluster.Config,
+	}
+
+	c.logger.V(0).Infof("Backing up cluster '%s' in %s to '%s'", options.Name, csd.BaseDir, options.Output)
+	if err = writeBackup(options.Output, csd.DataDir, manifest); err != nil {
+		return err
+	}
+	c.logger.V(0).Info("Backed up!")
+
+	return nil
+}
+
+// Restore restores the data directory of cluster from the backup. The cluster should be created with the config
+// in the backup, and its data directory should be empty.
+func (c *Cluster) Restore(_ context.Context, options *opt.RestoreOptions) error {
+	if !IsBackupFile(options.Input) {
+		return fmt.Errorf("invalid backup file '%s', it should be a '%s' file", options.Input, fileutils.TarGzExtension)
+	}
+
+	csd := c.mm.GetClusterScopeDirs()
+	entries, err := os.ReadDir(csd.DataDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("the data directory '%s' of cluster '%s' is not empty", csd.DataDir, options.Name)
+	}
+
+	// Extract the backup in the cluster directory, so that the data directory can be renamed to the destination.
+	tempDir, err := os.MkdirTemp(csd.BaseDir, ".restore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err = fileutils.Uncompress(options.Input, tempDir); err != nil {
+		return fmt.Errorf("invalid backup '%s': %v", options.Input, err)
+	}
+	if _, err = readBackupManifestFile(filepath.Join(tempDir, BackupManifestFile)); err != nil {
+		return fmt.Errorf("invalid backup '%s': %v", options.Input, err)
+	}
+
+	c.logger.V(0).Infof("Restoring the data of cluster '%s' from '%s' in %s", options.Name, options.Input, csd.DataDir)
+	if err = os.RemoveAll(csd.DataDir); err != nil {
+		return err
+	}
+	dataDir := filepath.Join(tempDir, backupDataDir)
+	if err = fileutils.EnsureDir(dataDir); err != nil {
+		return err
+	}
+	if err = os.Rename(dataDir, csd.DataDir); err != nil {
+		return err
+	}
+	c.logger.V(0).Info("Restored!")
+
+	return nil
+}
+
+// ReadBackupManifest reads the manifest in the backup without extracting the data.
+func ReadBackupManifest(file string) (*BackupManifest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup '%s': %v", file, err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("invalid backup '%s': missing %s", file, BackupManifestFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup '%s': %v", file, err)
+		}
+		if header.Typeflag != tar.TypeReg || path.Clean(header.Name) != BackupManifestFile {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := parseBackupManifest(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup '%s': %v", file, err)
+		}
+		return manifest, nil
+	}
+}
+
+func readBackupManifestFile(file string) (*BackupManifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseBackupManifest(data)
+}
+
+func parseBackupManifest(data []byte) (*BackupManifest, error) {
+	var manifest BackupManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if manifest.APIVersion != BackupAPIVersion {
+		return nil, fmt.Errorf("unsupported backup apiVersion '%s', expected '%s'", manifest.APIVersion, BackupAPIVersion)
+	}
+	if manifest.Config == nil {
+		return nil, fmt.Errorf("missing the config of cluster in manifest")
+	}
+	if err := config.ValidateConfig(manifest.Config); err != nil {
+		return nil, fmt.Errorf("invalid config of cluster in manifest: %v", err)
+	}
+	return &manifest, nil
+}
+
+// writeBackup writes the manifest and the files in dataDir to the '.tar.gz' file.
+func writeBackup(output, dataDir string, manifest *BackupManifest) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	// Write to the temporary file first, so that the incomplete backup will not be left.
+	f, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+".tmp-")
+	if err != nil {
+		return err
+	}
+	tempFile := f.Name()
+	defer os.Remove(tempFile)
+	defer f.Close()
+
+	// The temporary file is created with mode 0600, but the backup is readable like the other files.
+	if err = f.Chmod(0644); err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	if err = tw.WriteHeader(&tar.Header{
+		Name:    BackupManifestFile,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: manifest.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err = tw.Write(data); err != nil {
+		return err
+	}
+
+	if err = filepath.WalkDir(dataDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dataDir, file)
+		if err != nil {
+			return err
+		}
+		return addBackupEntry(tw, file, path.Join(backupDataDir, filepath.ToSlash(rel)))
+	}); err != nil {
+		return err
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = gw.Close(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile, output)
+}
+
+// addBackupEntry adds the directory, regular file or symlink to the backup, the other files such as sockets are skipped.
+func addBackupEntry(tw *tar.Writer, file, name string) error {
+	info, err := os.Lstat(file)
+	if err != nil {
+		return err
+	}
+
+	var link string
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if link, err = os.Readlink(file); err != nil {
+			return err
+		}
+	case info.IsDir():
+		name += "/"
+	case !info.Mode().IsRegular():
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	if err = tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.CopyN(tw, f, header.Size)
+	return err
+}
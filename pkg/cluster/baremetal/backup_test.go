@@ -0,0 +1,160 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package baremetal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/kind/pkg/log"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	l := logger.New(os.Stdout, log.Level(0), logger.WithColored())
+
+	// Prepare the stopped cluster that has the data of etcd and datanode.
+	clusterDir := filepath.Join(tempDir, metadata.BaseDir, "mycluster")
+	cfg := config.DefaultBareMetalConfig()
+	cfg.Cluster.Artifact.Version = "v0.4.1"
+	writeClusterMetadata(t, clusterDir, "mycluster", cfg, 4194304)
+	dataFiles := map[string]string{
+		"etcd/member/snap/db":          "etcd",
+		"datanode.0/home/data/table":   "table",
+		"datanode.0/wal/000001.log":    "wal",
+		"datanode.2/home/procedure/p1": "procedure",
+	}
+	for name, content := range dataFiles {
+		file := filepath.Join(clusterDir, metadata.ClusterDataDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	}
+
+	// The replica that has exited doesn't block the backup.
+	writeRuntimeRecord(t, clusterDir, "frontend.0", 4194304)
+
+	cluster, err := NewCluster(l, "mycluster", WithCreateNoDirs(), WithHomeDir(tempDir))
+	assert.NoError(t, err)
+
+	// The backup file should be a '.tar.gz' file.
+	err = cluster.Backup(context.TODO(), &opt.BackupOptions{Name: "mycluster", Output: filepath.Join(tempDir, "backup.zip")})
+	assert.Error(t, err)
+
+	backup := filepath.Join(tempDir, "backup.tar.gz")
+	assert.NoError(t, cluster.Backup(context.TODO(), &opt.BackupOptions{Name: "mycluster", Output: backup}))
+
+	manifest, err := ReadBackupManifest(backup)
+	assert.NoError(t, err)
+	assert.Equal(t, BackupAPIVersion, manifest.APIVersion)
+	assert.Equal(t, "mycluster", manifest.Name)
+	assert.Equal(t, "v0.4.1", manifest.Config.Cluster.Artifact.Version)
+
+	// The temporary file of backup is not left.
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".tmp")
+	}
+	info, err := os.Stat(backup)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// Restore the backup to a new cluster.
+	restored, err := NewCluster(l, "restored", WithReplaceConfig(manifest.Config), WithHomeDir(tempDir))
+	assert.NoError(t, err)
+	assert.NoError(t, restored.Restore(context.TODO(), &opt.RestoreOptions{Name: "restored", Input: backup}))
+
+	restoredDir := filepath.Join(tempDir, metadata.BaseDir, "restored")
+	for name, content := range dataFiles {
+		data, err := os.ReadFile(filepath.Join(restoredDir, metadata.ClusterDataDir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+	entries, err = os.ReadDir(restoredDir)
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".restore-")
+	}
+
+	// The data of the cluster is not overwritten.
+	assert.Error(t, restored.Restore(context.TODO(), &opt.RestoreOptions{Name: "restored", Input: backup}))
+}
+
+func TestBackupRunningCluster(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// The cluster whose foreground process is alive is running.
+	clusterDir := filepath.Join(tempDir, metadata.BaseDir, "mycluster")
+	writeClusterMetadata(t, clusterDir, "mycluster", config.DefaultBareMetalConfig(), os.Getpid())
+
+	cluster, err := NewCluster(logger.New(os.Stdout, log.Level(0), logger.WithColored()), "mycluster",
+		WithCreateNoDirs(), WithHomeDir(tempDir))
+	assert.NoError(t, err)
+
+	backup := filepath.Join(tempDir, "backup.tar.gz")
+	err = cluster.Backup(context.TODO(), &opt.BackupOptions{Name: "mycluster", Output: backup})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "please stop it before backing up")
+	_, err = os.Stat(backup)
+	assert.True(t, os.IsNotExist(err))
+
+	// The cluster whose replica outlives the foreground process is also running.
+	writeClusterMetadata(t, clusterDir, "mycluster", config.DefaultBareMetalConfig(), 4194304)
+	writeRuntimeRecord(t, clusterDir, "datanode.0", os.Getpid())
+
+	err = cluster.Backup(context.TODO(), &opt.BackupOptions{Name: "mycluster", Output: backup})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "'datanode.0' of cluster 'mycluster' is still running")
+	_, err = os.Stat(backup)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func writeClusterMetadata(t *testing.T, clusterDir, name string, cfg *config.BareMetalClusterConfig, pid int) {
+	out, err := yaml.Marshal(&config.BareMetalClusterMetadata{
+		Config:        cfg,
+		CreationDate:  time.Now(),
+		ClusterDir:    clusterDir,
+		ForegroundPid: pid,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(clusterDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(clusterDir, name+".yaml"), out, 0644))
+}
+
+func writeRuntimeRecord(t *testing.T, clusterDir, replica string, pid int) {
+	out, err := yaml.Marshal(&components.RuntimeRecord{Pid: pid})
+	assert.NoError(t, err)
+	pidDir := filepath.Join(clusterDir, metadata.ClusterPidsDir, replica)
+	assert.NoError(t, os.MkdirAll(pidDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(pidDir, components.RuntimeFile), out, 0644))
+}
@@ -18,6 +18,7 @@ package baremetal
 
 import (
 	"context"
+	"fmt"
 	"os/signal"
 	"sync"
 	"syscall"
@@ -31,10 +32,14 @@ import (
 )
 
 type Cluster struct {
+	name         string
+	homeDir      string
 	config       *config.BareMetalClusterConfig
 	createNoDirs bool
 	enableCache  bool
 
+	artifactsOptions []artifacts.Option
+
 	am artifacts.Manager
 	mm metadata.Manager
 	cc *ClusterComponents
@@ -43,6 +48,10 @@ type Cluster struct {
 	stop   context.CancelFunc
 	ctx    context.Context
 	wg     sync.WaitGroup
+
+	// failure is why the cluster is stopped by the supervisor, it's empty if the cluster is stopped by user.
+	failure string
+	mu      sync.Mutex
 }
 
 // ClusterComponents describes all the components need to be deployed under bare-metal mode.
@@ -84,16 +93,36 @@ func WithEnableCache(enableCache bool) Option {
 	}
 }
 
+// WithArtifactsOptions sets the options of artifacts manager that downloads the binaries.
+func WithArtifactsOptions(opts ...artifacts.Option) Option {
+	return func(c *Cluster) {
+		c.artifactsOptions = append(c.artifactsOptions, opts...)
+	}
+}
+
+// WithHomeDir sets the home directory of gtctl that stores the metadata of cluster.
+func WithHomeDir(dir string) Option {
+	return func(c *Cluster) {
+		c.homeDir = dir
+	}
+}
+
 func WithCreateNoDirs() Option {
 	return func(c *Cluster) {
 		c.createNoDirs = true
 	}
 }
 
+// Config returns the config of cluster, the versions of artifacts in it are resolved after the cluster is created.
+func (c *Cluster) Config() *config.BareMetalClusterConfig {
+	return c.config
+}
+
 func NewCluster(l logger.Logger, clusterName string, opts ...Option) (cluster.Operations, error) {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 
 	c := &Cluster{
+		name:   clusterName,
 		logger: l,
 		config: config.DefaultBareMetalConfig(),
 		ctx:    ctx,
@@ -111,14 +140,15 @@ func NewCluster(l logger.Logger, clusterName string, opts ...Option) (cluster.Op
 	}
 
 	// Configure Metadata Manager
-	mm, err := metadata.New("")
+	mm, err := metadata.New(c.homeDir)
 	if err != nil {
 		return nil, err
 	}
 	c.mm = mm
 
-	// Configure Artifact Manager.
-	am, err := artifacts.NewManager(l)
+	// Configure Artifact Manager, the artifacts are cached in the working directory of metadata by default.
+	artifactsOptions := append([]artifacts.Option{artifacts.WithWorkingDir(mm.GetWorkingDir())}, c.artifactsOptions...)
+	am, err := artifacts.NewManager(l, artifactsOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -140,3 +170,29 @@ func NewCluster(l logger.Logger, clusterName string, opts ...Option) (cluster.Op
 
 	return c, nil
 }
+
+// setState records the state of cluster in the cluster registry.
+func (c *Cluster) setState(state metadata.ClusterState, reason string) {
+	if err := c.mm.SetClusterState(metadata.ClusterModeBareMetal, "", c.name, state, reason); err != nil {
+		c.logger.V(3).Infof("failed to record the state of cluster '%s': %v", c.name, err)
+	}
+}
+
+// onComponentExit is called by the supervisor when one process of component crashes, then the whole cluster is stopped.
+func (c *Cluster) onComponentExit(name string, err error) {
+	c.mu.Lock()
+	if len(c.failure) == 0 {
+		c.failure = fmt.Sprintf("component '%s' exited unexpectedly: %v", name, err)
+	}
+	failure := c.failure
+	c.mu.Unlock()
+
+	c.setState(metadata.ClusterStateDegraded, failure)
+	c.stop()
+}
+
+func (c *Cluster) failureReason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failure
+}
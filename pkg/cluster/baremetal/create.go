@@ -28,6 +28,7 @@ import (
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
@@ -62,6 +63,13 @@ func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error
 		return err
 	}
 
+	// Record the resolved versions of artifacts.
+	if !c.createNoDirs {
+		if err := c.mm.UpdateClusterConfig(c.config); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -71,7 +79,7 @@ func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions)
 	}
 	clusterOpt := options.Cluster
 
-	var binPath string
+	var binPath, version string
 	if c.config.Cluster.Artifact != nil {
 		if c.config.Cluster.Artifact.Local != "" {
 			binPath = c.config.Cluster.Artifact.Local
@@ -86,6 +94,10 @@ func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions)
 			if err != nil {
 				return err
 			}
+			if !artifacts.IsOCIReference(c.config.Cluster.Artifact.Version) {
+				// The OCI reference is kept to pull the same artifact again.
+				c.config.Cluster.Artifact.Version = src.Version
+			}
 
 			destDir, err := c.mm.AllocateArtifactFilePath(src, false)
 			if err != nil {
@@ -100,21 +112,23 @@ func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions)
 			artifactFile, err := c.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{
 				EnableCache:      c.enableCache,
 				BinaryInstallDir: installDir,
+				Spinner:          options.Spinner,
 			})
 			if err != nil {
 				return err
 			}
 			binPath = artifactFile
+			version = src.Version
 		}
 	}
 
-	if err := c.cc.MetaSrv.Start(c.ctx, c.stop, binPath); err != nil {
+	if err := c.cc.MetaSrv.Start(c.ctx, c.onComponentExit, binPath, version); err != nil {
 		return err
 	}
-	if err := c.cc.Datanode.Start(c.ctx, c.stop, binPath); err != nil {
+	if err := c.cc.Datanode.Start(c.ctx, c.onComponentExit, binPath, version); err != nil {
 		return err
 	}
-	if err := c.cc.Frontend.Start(c.ctx, c.stop, binPath); err != nil {
+	if err := c.cc.Frontend.Start(c.ctx, c.onComponentExit, binPath, version); err != nil {
 		return err
 	}
 
@@ -127,7 +141,7 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 	}
 	etcdOpt := options.Etcd
 
-	var binPath string
+	var binPath, version string
 	if c.config.Etcd.Artifact != nil {
 		if c.config.Etcd.Artifact.Local != "" {
 			binPath = c.config.Etcd.Artifact.Local
@@ -142,6 +156,10 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 			if err != nil {
 				return err
 			}
+			if !artifacts.IsOCIReference(c.config.Etcd.Artifact.Version) {
+				// The OCI reference is kept to pull the same artifact again.
+				c.config.Etcd.Artifact.Version = src.Version
+			}
 
 			destDir, err := c.mm.AllocateArtifactFilePath(src, false)
 			if err != nil {
@@ -156,15 +174,17 @@ func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOpti
 			artifactFile, err := c.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{
 				EnableCache:      c.enableCache,
 				BinaryInstallDir: installDir,
+				Spinner:          options.Spinner,
 			})
 			if err != nil {
 				return err
 			}
 			binPath = artifactFile
+			version = src.Version
 		}
 	}
 
-	if err := c.cc.Etcd.Start(c.ctx, c.stop, binPath); err != nil {
+	if err := c.cc.Etcd.Start(c.ctx, c.onComponentExit, binPath, version); err != nil {
 		return err
 	}
 	if err := c.checkEtcdHealth(binPath); err != nil {
@@ -249,12 +269,24 @@ func (c *Cluster) Wait(ctx context.Context, close bool) error {
 }
 
 func (c *Cluster) wait(_ context.Context) error {
-	c.wg.Wait()
-
 	// We ignore the context from input params, since
 	// it is not the context of current cluster.
 	<-c.ctx.Done()
 
+	failure := c.failureReason()
+	if len(failure) == 0 {
+		c.setState(metadata.ClusterStateStopping, "Interrupted by signal")
+	}
+
+	// Wait for all the sub-processes to exit.
+	c.wg.Wait()
+
+	if len(failure) > 0 {
+		c.setState(metadata.ClusterStateFailed, failure)
+	} else {
+		c.setState(metadata.ClusterStateStopped, "")
+	}
+
 	csd := c.mm.GetClusterScopeDirs()
 	c.logger.V(0).Infof("Cluster is shutting down, don't worry, it still remain in %s", logger.Bold(csd.BaseDir))
 	return nil
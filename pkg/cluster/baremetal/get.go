@@ -23,12 +23,16 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	greptimedbclusterv1alpha1 "github.com/GreptimeTeam/greptimedb-operator/apis/v1alpha1"
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v3"
 
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+	"github.com/GreptimeTeam/gtctl/pkg/components"
 	cfg "github.com/GreptimeTeam/gtctl/pkg/config"
 	"github.com/GreptimeTeam/gtctl/pkg/metadata"
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
@@ -95,21 +99,29 @@ func (c *Cluster) renderGetView(table *tablewriter.Table, data *cfg.BareMetalClu
 
 func collectClusterInfoFromBareMetal(data *cfg.BareMetalClusterMetadata) (
 	headers, footers []string, bulk [][]string) {
-	headers = []string{"COMPONENT", "PID"}
+	headers = []string{"COMPONENT", "PID", "STATUS", "RESTARTS", "ENDPOINTS"}
 
 	pidsDir := path.Join(data.ClusterDir, metadata.ClusterPidsDir)
-	pidsMap := collectPidsForBareMetal(pidsDir)
+	records := collectRuntimeRecordsForBareMetal(pidsDir)
 
 	var (
 		date = data.CreationDate.String()
+		row  = func(name, key, prefix string) []string {
+			record, ok := records[key]
+			if !ok {
+				return []string{name, "N/A", "N/A", "N/A", "N/A"}
+			}
+			return []string{
+				name,
+				fmt.Sprintf("%s%d", prefix, record.Pid),
+				runtimeStatus(record),
+				strconv.Itoa(record.RestartCount),
+				formatAddresses(record.Addresses),
+			}
+		}
 		rows = func(name string, replicas int) {
 			for i := 0; i < replicas; i++ {
-				key := fmt.Sprintf("%s.%d", name, i)
-				pid := "N/A"
-				if val, ok := pidsMap[key]; ok {
-					pid = fmt.Sprintf(".%d: %s", i, val)
-				}
-				bulk = append(bulk, []string{name, pid})
+				bulk = append(bulk, row(name, fmt.Sprintf("%s.%d", name, i), fmt.Sprintf(".%d: ", i)))
 			}
 		}
 	)
@@ -118,7 +130,7 @@ func collectClusterInfoFromBareMetal(data *cfg.BareMetalClusterMetadata) (
 	rows(string(greptimedbclusterv1alpha1.DatanodeComponentKind), data.Config.Cluster.Datanode.Replicas)
 	rows(string(greptimedbclusterv1alpha1.MetaComponentKind), data.Config.Cluster.MetaSrv.Replicas)
 
-	bulk = append(bulk, []string{"etcd", pidsMap["etcd"]})
+	bulk = append(bulk, row("etcd", "etcd", ""))
 
 	config, err := yaml.Marshal(data.Config)
 	footers = []string{
@@ -136,7 +148,37 @@ func collectClusterInfoFromBareMetal(data *cfg.BareMetalClusterMetadata) (
 	return headers, footers, bulk
 }
 
-// collectPidsForBareMetal returns the pid of each component.
+// collectRuntimeRecordsForBareMetal returns the runtime record of each replica. The replica that is started by
+// the old versions of gtctl has only the pid file, so its record has only the pid.
+func collectRuntimeRecordsForBareMetal(pidsDir string) map[string]*components.RuntimeRecord {
+	ret := make(map[string]*components.RuntimeRecord)
+
+	entries, err := os.ReadDir(pidsDir)
+	if err != nil {
+		return ret
+	}
+
+	pids := collectPidsForBareMetal(pidsDir)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		record, err := components.ReadRuntimeRecord(filepath.Join(pidsDir, entry.Name()))
+		if err != nil {
+			pid, err := strconv.Atoi(strings.TrimSpace(pids[entry.Name()]))
+			if err != nil {
+				continue
+			}
+			record = &components.RuntimeRecord{Pid: pid}
+		}
+		ret[entry.Name()] = record
+	}
+
+	return ret
+}
+
+// collectPidsForBareMetal returns the pid of each component that has the pid file.
 func collectPidsForBareMetal(pidsDir string) map[string]string {
 	ret := make(map[string]string)
 
@@ -146,8 +188,11 @@ func collectPidsForBareMetal(pidsDir string) map[string]string {
 				return nil
 			}
 
-			pidPath := filepath.Join(path, "pid")
+			pidPath := filepath.Join(path, components.PidFile)
 			pid, err := os.ReadFile(pidPath)
+			if os.IsNotExist(err) {
+				return nil
+			}
 			if err != nil {
 				return err
 			}
@@ -161,3 +206,30 @@ func collectPidsForBareMetal(pidsDir string) map[string]string {
 
 	return ret
 }
+
+// runtimeStatus returns whether the process of the replica is running, the process whose pid is reused
+// by another process is not running.
+func runtimeStatus(record *components.RuntimeRecord) string {
+	if record.IsAlive() {
+		return "Running"
+	}
+	if record.LastExitCode != nil {
+		return fmt.Sprintf("Exited(%d)", *record.LastExitCode)
+	}
+	return "Not running"
+}
+
+// formatAddresses formats the addresses like 'http=127.0.0.1:4000,mysql=127.0.0.1:4002' in the order of protocols.
+func formatAddresses(addresses map[string]string) string {
+	if len(addresses) == 0 {
+		return "N/A"
+	}
+
+	var pairs []string
+	for protocol, addr := range addresses {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", protocol, addr))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
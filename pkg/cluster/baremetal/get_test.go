@@ -17,10 +17,13 @@
 package baremetal
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/GreptimeTeam/gtctl/pkg/components"
 )
 
 func TestCollectPidsForBareMetal(t *testing.T) {
@@ -35,3 +38,25 @@ func TestCollectPidsForBareMetal(t *testing.T) {
 
 	assert.Equal(t, want, ret)
 }
+
+func TestCollectRuntimeRecordsForBareMetal(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// The 'frontend.0' is started by the current gtctl and the 'etcd' is started by the old one.
+	runtimeRecord := "pid: 123\nbinary: /bin/greptime\naddresses:\n  mysql: 127.0.0.1:4002\n  http: 127.0.0.1:4000\nrestartCount: 2\nlastExitCode: 1\n"
+	assert.NoError(t, os.MkdirAll(filepath.Join(tempDir, "frontend.0"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "frontend.0", components.RuntimeFile), []byte(runtimeRecord), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(tempDir, "etcd"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "etcd", components.PidFile), []byte("456"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(tempDir, "datanode.0"), 0755))
+
+	records := collectRuntimeRecordsForBareMetal(tempDir)
+	assert.Len(t, records, 2)
+	assert.Equal(t, 123, records["frontend.0"].Pid)
+	assert.Equal(t, 2, records["frontend.0"].RestartCount)
+	assert.Equal(t, "http=127.0.0.1:4000,mysql=127.0.0.1:4002", formatAddresses(records["frontend.0"].Addresses))
+	assert.Equal(t, 456, records["etcd"].Pid)
+	assert.Equal(t, "N/A", formatAddresses(records["etcd"].Addresses))
+}
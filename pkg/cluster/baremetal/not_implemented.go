@@ -34,3 +34,7 @@ func (c *Cluster) Scale(ctx context.Context, options *opt.ScaleOptions) error {
 func (c *Cluster) Connect(ctx context.Context, options *opt.ConnectOptions) error {
 	return fmt.Errorf("do not support")
 }
+
+func (c *Cluster) Apply(ctx context.Context, options *opt.CreateOptions) error {
+	return fmt.Errorf("do not support")
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+// Apply updates an existing cluster to the desired state. Since all the manifests
+// are applied by server-side apply, it just re-renders the charts and applies them.
+func (c *Cluster) Apply(ctx context.Context, options *opt.CreateOptions) error {
+	if !c.dryRun {
+		_, err := c.get(ctx, &opt.GetOptions{
+			Namespace: options.Namespace,
+			Name:      options.Name,
+		})
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("cluster '%s' in namespace '%s' not found, please create it first", options.Name, options.Namespace)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.deploy(ctx, options, "Applying")
+}
@@ -19,6 +19,7 @@ package kubernetes
 import (
 	"time"
 
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	"github.com/GreptimeTeam/gtctl/pkg/cluster"
 	"github.com/GreptimeTeam/gtctl/pkg/helm"
 	"github.com/GreptimeTeam/gtctl/pkg/kube"
@@ -30,8 +31,16 @@ type Cluster struct {
 	client     *kube.Client
 	logger     logger.Logger
 
-	timeout time.Duration
-	dryRun  bool
+	timeout     time.Duration
+	dryRun      bool
+	kubeconfig  string
+	kubeContext string
+	homeDir     string
+
+	// chartVersions is the resolved versions of the charts that are deployed, keyed by the chart name.
+	chartVersions map[string]string
+
+	artifactsOptions []artifacts.Option
 }
 
 type Option func(cluster *Cluster)
@@ -50,23 +59,56 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
-func NewCluster(l logger.Logger, opts ...Option) (cluster.Operations, error) {
-	hl, err := helm.NewLoader(l)
-	if err != nil {
-		return nil, err
+// WithKubeConfig sets the kubeconfig and the context in it that Cluster uses.
+func WithKubeConfig(kubeconfig, kubeContext string) Option {
+	return func(c *Cluster) {
+		c.kubeconfig = kubeconfig
+		c.kubeContext = kubeContext
 	}
+}
+
+// WithHomeDir sets the home directory of gtctl that stores the metadata and the cached charts.
+func WithHomeDir(dir string) Option {
+	return func(c *Cluster) {
+		c.homeDir = dir
+	}
+}
 
+// WithArtifactsOptions sets the options of artifacts manager that downloads the charts.
+func WithArtifactsOptions(opts ...artifacts.Option) Option {
+	return func(c *Cluster) {
+		c.artifactsOptions = append(c.artifactsOptions, opts...)
+	}
+}
+
+// ChartVersions returns the versions of the charts that the cluster uses, keyed by the chart name.
+// The versions are resolved after the cluster is created or applied, and the local chart takes the place of version.
+func (c *Cluster) ChartVersions() map[string]string {
+	return c.chartVersions
+}
+
+func NewCluster(l logger.Logger, opts ...Option) (cluster.Operations, error) {
 	c := &Cluster{
-		helmLoader: hl,
-		logger:     l,
+		logger:        l,
+		chartVersions: make(map[string]string),
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	loaderOpts := []helm.Option{helm.WithArtifactsOptions(c.artifactsOptions...)}
+	if len(c.homeDir) > 0 {
+		loaderOpts = append(loaderOpts, helm.WithHomeDir(c.homeDir))
+	}
+	hl, err := helm.NewLoader(l, loaderOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.helmLoader = hl
+
 	var client *kube.Client
 	if !c.dryRun {
-		client, err = kube.NewClient("")
+		client, err = kube.NewClient(c.kubeconfig, c.kubeContext)
 		if err != nil {
 			return nil, err
 		}
@@ -19,6 +19,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
@@ -32,23 +33,29 @@ const (
 )
 
 func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error {
+	return c.deploy(ctx, options, "Installing")
+}
+
+// deploy renders and applies the operator, etcd and cluster charts in order.
+// The action is only used to show the progress of deploying.
+func (c *Cluster) deploy(ctx context.Context, options *opt.CreateOptions, action string) error {
 	spinner := options.Spinner
 
 	withSpinner := func(target string, f func(context.Context, *opt.CreateOptions) error) error {
 		if !c.dryRun && spinner != nil {
-			spinner.Start(fmt.Sprintf("Installing %s...", target))
+			spinner.Start(fmt.Sprintf("%s %s...", action, target))
 		}
 
 		if err := f(ctx, options); err != nil {
 			if spinner != nil {
-				spinner.Stop(false, fmt.Sprintf("Installing %s failed", target))
+				spinner.Stop(false, fmt.Sprintf("%s %s failed", action, target))
 			}
 			return err
 		}
 
 		if !c.dryRun {
 			if spinner != nil {
-				spinner.Stop(true, fmt.Sprintf("Installing %s successfully 🎉", target))
+				spinner.Stop(true, fmt.Sprintf("%s %s successfully 🎉", action, target))
 			}
 		}
 		return nil
@@ -69,126 +76,198 @@ func (c *Cluster) Create(ctx context.Context, options *opt.CreateOptions) error
 
 // createOperator creates GreptimeDB Operator.
 func (c *Cluster) createOperator(ctx context.Context, options *opt.CreateOptions) error {
-	if options.Operator == nil {
-		return fmt.Errorf("missing create greptimedb operator options")
-	}
-	operatorOpt := options.Operator
-	resourceName, resourceNamespace := OperatorName(), options.Namespace
-
-	if operatorOpt.UseGreptimeCNArtifacts && len(operatorOpt.ImageRegistry) == 0 {
-		operatorOpt.ConfigValues += fmt.Sprintf("image.registry=%s,", AliCloudRegistry)
+	opts, err := OperatorLoadOptions(options)
+	if err != nil {
+		return err
 	}
 
-	opts := &helm.LoadOptions{
-		ReleaseName:   resourceName,
-		Namespace:     resourceNamespace,
-		ChartName:     artifacts.GreptimeDBOperatorChartName,
-		ChartVersion:  operatorOpt.GreptimeDBOperatorChartVersion,
-		FromCNRegion:  operatorOpt.UseGreptimeCNArtifacts,
-		ValuesOptions: *operatorOpt,
-		EnableCache:   true,
-		ValuesFile:    operatorOpt.ValuesFile,
-	}
-	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
+	rendered, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
 	if err != nil {
 		return err
 	}
 
+	c.chartVersions[opts.ChartName] = rendered.Version
+
 	if c.dryRun {
-		c.logger.V(0).Info(string(manifests))
+		c.logger.V(0).Info(string(rendered.Manifests))
 		return nil
 	}
 
-	if err = c.client.Apply(ctx, manifests); err != nil {
+	if err = c.client.Apply(ctx, rendered.Manifests); err != nil {
 		return err
 	}
 
-	return c.client.WaitForDeploymentReady(ctx, resourceName, resourceNamespace, c.timeout)
+	return c.client.WaitForDeploymentReady(ctx, opts.ReleaseName, opts.Namespace, c.timeout)
 }
 
 // createCluster creates GreptimeDB cluster.
 func (c *Cluster) createCluster(ctx context.Context, options *opt.CreateOptions) error {
-	if options.Cluster == nil {
-		return fmt.Errorf("missing create greptimedb cluster options")
-	}
-	clusterOpt := options.Cluster
-	resourceName, resourceNamespace := options.Name, options.Namespace
-
-	if clusterOpt.UseGreptimeCNArtifacts && len(clusterOpt.ImageRegistry) == 0 {
-		clusterOpt.ConfigValues += fmt.Sprintf("image.registry=%s,initializer.registry=%s,", AliCloudRegistry, AliCloudRegistry)
+	opts, err := ClusterLoadOptions(options)
+	if err != nil {
+		return err
 	}
 
-	opts := &helm.LoadOptions{
-		ReleaseName:   resourceName,
-		Namespace:     resourceNamespace,
-		ChartName:     artifacts.GreptimeDBClusterChartName,
-		ChartVersion:  clusterOpt.GreptimeDBChartVersion,
-		FromCNRegion:  clusterOpt.UseGreptimeCNArtifacts,
-		ValuesOptions: *clusterOpt,
-		EnableCache:   true,
-		ValuesFile:    clusterOpt.ValuesFile,
-	}
-	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
+	rendered, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
 	if err != nil {
 		return err
 	}
 
+	c.chartVersions[opts.ChartName] = rendered.Version
+
 	if c.dryRun {
-		c.logger.V(0).Info(string(manifests))
+		c.logger.V(0).Info(string(rendered.Manifests))
 		return nil
 	}
 
-	if err = c.client.Apply(ctx, manifests); err != nil {
+	if err = c.client.Apply(ctx, rendered.Manifests); err != nil {
 		return err
 	}
 
-	return c.client.WaitForClusterReady(ctx, resourceName, resourceNamespace, c.timeout)
+	return c.client.WaitForClusterReady(ctx, opts.ReleaseName, opts.Namespace, c.timeout)
 }
 
 // createEtcdCluster creates Etcd cluster.
 func (c *Cluster) createEtcdCluster(ctx context.Context, options *opt.CreateOptions) error {
-	if options.Etcd == nil {
-		return fmt.Errorf("missing create etcd cluster options")
-	}
-	etcdOpt := options.Etcd
-	resourceName, resourceNamespace := EtcdClusterName(options.Name), options.Namespace
-
-	etcdOpt.ConfigValues += disableRBACConfig
-	if etcdOpt.UseGreptimeCNArtifacts && len(etcdOpt.ImageRegistry) == 0 {
-		etcdOpt.ConfigValues += fmt.Sprintf("image.registry=%s,", AliCloudRegistry)
+	opts, err := EtcdLoadOptions(options)
+	if err != nil {
+		return err
 	}
 
-	opts := &helm.LoadOptions{
-		ReleaseName:   resourceName,
-		Namespace:     resourceNamespace,
-		ChartName:     artifacts.EtcdChartName,
-		ChartVersion:  artifacts.DefaultEtcdChartVersion,
-		FromCNRegion:  etcdOpt.UseGreptimeCNArtifacts,
-		ValuesOptions: *etcdOpt,
-		EnableCache:   true,
-		ValuesFile:    etcdOpt.ValuesFile,
-	}
-	manifests, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
+	rendered, err := c.helmLoader.LoadAndRenderChart(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("error while loading helm chart: %v", err)
 	}
 
+	c.chartVersions[opts.ChartName] = rendered.Version
+
 	if c.dryRun {
-		c.logger.V(0).Info(string(manifests))
+		c.logger.V(0).Info(string(rendered.Manifests))
 		return nil
 	}
 
-	if err = c.client.Apply(ctx, manifests); err != nil {
+	if err = c.client.Apply(ctx, rendered.Manifests); err != nil {
 		return fmt.Errorf("error while applying helm chart: %v", err)
 	}
 
-	return c.client.WaitForEtcdReady(ctx, resourceName, resourceNamespace, c.timeout)
+	return c.client.WaitForEtcdReady(ctx, opts.ReleaseName, opts.Namespace, c.timeout)
+}
+
+// OperatorLoadOptions returns the options to load and render the GreptimeDB Operator chart.
+func OperatorLoadOptions(options *opt.CreateOptions) (*helm.LoadOptions, error) {
+	if options.Operator == nil {
+		return nil, fmt.Errorf("missing create greptimedb operator options")
+	}
+	operatorOpt := *options.Operator
+
+	if operatorOpt.UseGreptimeCNArtifacts && len(operatorOpt.ImageRegistry) == 0 {
+		operatorOpt.ConfigValues = appendConfigValues(operatorOpt.ConfigValues, fmt.Sprintf("image.registry=%s,", AliCloudRegistry))
+	}
+
+	return &helm.LoadOptions{
+		ReleaseName:   OperatorName(),
+		Namespace:     namespaceOrDefault(operatorOpt.Namespace, options.Namespace),
+		ChartName:     artifacts.GreptimeDBOperatorChartName,
+		ChartVersion:  operatorOpt.GreptimeDBOperatorChartVersion,
+		ChartPath:     operatorOpt.GreptimeDBOperatorChartPath,
+		FromCNRegion:  operatorOpt.UseGreptimeCNArtifacts,
+		ValuesOptions: operatorOpt,
+		EnableCache:   true,
+		ValuesFiles:   operatorOpt.ValuesFiles,
+		Values:        operatorOpt.Values,
+	}, nil
+}
+
+// ClusterLoadOptions returns the options to load and render the GreptimeDB cluster chart.
+func ClusterLoadOptions(options *opt.CreateOptions) (*helm.LoadOptions, error) {
+	if options.Cluster == nil {
+		return nil, fmt.Errorf("missing create greptimedb cluster options")
+	}
+	clusterOpt := *options.Cluster
+
+	if clusterOpt.UseGreptimeCNArtifacts && len(clusterOpt.ImageRegistry) == 0 {
+		clusterOpt.ConfigValues = appendConfigValues(clusterOpt.ConfigValues, fmt.Sprintf("image.registry=%s,initializer.registry=%s,", AliCloudRegistry, AliCloudRegistry))
+	}
+
+	// The cluster uses the etcd cluster that created along with it by default,
+	// so the endpoints should be in the same namespace as the etcd is deployed.
+	if len(clusterOpt.EtcdEndPoints) == 0 {
+		etcdNamespace := options.Namespace
+		if options.Etcd != nil {
+			etcdNamespace = namespaceOrDefault(options.Etcd.Namespace, options.Namespace)
+		}
+		clusterOpt.EtcdEndPoints = EtcdEndpoints(options.Name, etcdNamespace)
+	}
+
+	return &helm.LoadOptions{
+		ReleaseName:   options.Name,
+		Namespace:     options.Namespace,
+		ChartName:     artifacts.GreptimeDBClusterChartName,
+		ChartVersion:  clusterOpt.GreptimeDBChartVersion,
+		ChartPath:     clusterOpt.GreptimeDBChartPath,
+		FromCNRegion:  clusterOpt.UseGreptimeCNArtifacts,
+		ValuesOptions: clusterOpt,
+		EnableCache:   true,
+		ValuesFiles:   clusterOpt.ValuesFiles,
+		Values:        clusterOpt.Values,
+	}, nil
+}
+
+// EtcdLoadOptions returns the options to load and render the etcd chart.
+func EtcdLoadOptions(options *opt.CreateOptions) (*helm.LoadOptions, error) {
+	if options.Etcd == nil {
+		return nil, fmt.Errorf("missing create etcd cluster options")
+	}
+	etcdOpt := *options.Etcd
+
+	etcdOpt.ConfigValues = appendConfigValues(etcdOpt.ConfigValues, disableRBACConfig)
+	if etcdOpt.UseGreptimeCNArtifacts && len(etcdOpt.ImageRegistry) == 0 {
+		etcdOpt.ConfigValues = appendConfigValues(etcdOpt.ConfigValues, fmt.Sprintf("image.registry=%s,", AliCloudRegistry))
+	}
+
+	chartVersion := etcdOpt.EtcdChartVersion
+	if len(chartVersion) == 0 {
+		chartVersion = artifacts.DefaultEtcdChartVersion
+	}
+
+	return &helm.LoadOptions{
+		ReleaseName:   EtcdClusterName(options.Name),
+		Namespace:     namespaceOrDefault(etcdOpt.Namespace, options.Namespace),
+		ChartName:     artifacts.EtcdChartName,
+		ChartVersion:  chartVersion,
+		ChartPath:     etcdOpt.EtcdChartPath,
+		FromCNRegion:  etcdOpt.UseGreptimeCNArtifacts,
+		ValuesOptions: etcdOpt,
+		EnableCache:   true,
+		ValuesFiles:   etcdOpt.ValuesFiles,
+		Values:        etcdOpt.Values,
+	}, nil
 }
 
 func EtcdClusterName(clusterName string) string {
 	return fmt.Sprintf("%s-etcd", clusterName)
 }
 
+// EtcdEndpoints returns the endpoints of the etcd cluster that created along with the cluster.
+func EtcdEndpoints(clusterName, namespace string) string {
+	return fmt.Sprintf("%s.%s:2379", EtcdClusterName(clusterName), namespace)
+}
+
 func OperatorName() string {
 	return "greptimedb-operator"
 }
+
+// namespaceOrDefault returns the namespace of the component, it falls back to the namespace of cluster.
+func namespaceOrDefault(namespace, clusterNamespace string) string {
+	if len(namespace) > 0 {
+		return namespace
+	}
+	return clusterNamespace
+}
+
+// appendConfigValues appends the extra values to the values that may be set in command line.
+func appendConfigValues(values, extra string) string {
+	if len(values) > 0 && !strings.HasSuffix(values, ",") {
+		values += ","
+	}
+	return values + extra
+}
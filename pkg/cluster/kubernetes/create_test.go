@@ -0,0 +1,83 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+func TestLoadOptionsNamespaces(t *testing.T) {
+	options := &opt.CreateOptions{
+		Namespace: "greptimedb",
+		Name:      "mycluster",
+		Operator: &opt.CreateOperatorOptions{
+			Namespace: "greptimedb-admin",
+		},
+		Etcd: &opt.CreateEtcdOptions{
+			Namespace: "etcd",
+		},
+		Cluster: &opt.CreateClusterOptions{},
+	}
+
+	operatorOpts, err := OperatorLoadOptions(options)
+	assert.NoError(t, err)
+	assert.Equal(t, "greptimedb-admin", operatorOpts.Namespace)
+
+	etcdOpts, err := EtcdLoadOptions(options)
+	assert.NoError(t, err)
+	assert.Equal(t, "etcd", etcdOpts.Namespace)
+
+	clusterOpts, err := ClusterLoadOptions(options)
+	assert.NoError(t, err)
+	assert.Equal(t, "greptimedb", clusterOpts.Namespace)
+
+	// The endpoints should point to the namespace that etcd is deployed.
+	clusterOpt, ok := clusterOpts.ValuesOptions.(opt.CreateClusterOptions)
+	assert.True(t, ok)
+	assert.Equal(t, "mycluster-etcd.etcd:2379", clusterOpt.EtcdEndPoints)
+}
+
+func TestLoadOptionsDefaultNamespaces(t *testing.T) {
+	options := &opt.CreateOptions{
+		Namespace: "greptimedb",
+		Name:      "mycluster",
+		Operator:  &opt.CreateOperatorOptions{},
+		Etcd:      &opt.CreateEtcdOptions{},
+		Cluster: &opt.CreateClusterOptions{
+			EtcdEndPoints: "etcd.example.com:2379",
+		},
+	}
+
+	operatorOpts, err := OperatorLoadOptions(options)
+	assert.NoError(t, err)
+	assert.Equal(t, "greptimedb", operatorOpts.Namespace)
+
+	etcdOpts, err := EtcdLoadOptions(options)
+	assert.NoError(t, err)
+	assert.Equal(t, "greptimedb", etcdOpts.Namespace)
+
+	// The endpoints that set explicitly should be kept.
+	clusterOpts, err := ClusterLoadOptions(options)
+	assert.NoError(t, err)
+	clusterOpt, ok := clusterOpts.ValuesOptions.(opt.CreateClusterOptions)
+	assert.True(t, ok)
+	assert.Equal(t, "etcd.example.com:2379", clusterOpt.EtcdEndPoints)
+}
@@ -0,0 +1,32 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	opt "github.com/GreptimeTeam/gtctl/pkg/cluster"
+)
+
+func (c *Cluster) Backup(ctx context.Context, options *opt.BackupOptions) error {
+	return fmt.Errorf("do not support")
+}
+
+func (c *Cluster) Restore(ctx context.Context, options *opt.RestoreOptions) error {
+	return fmt.Errorf("do not support")
+}
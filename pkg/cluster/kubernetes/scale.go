@@ -40,6 +40,7 @@ func (c *Cluster) Scale(ctx context.Context, options *opt.ScaleOptions) error {
 	if err = c.client.UpdateCluster(ctx, options.Namespace, cluster); err != nil {
 		return err
 	}
+	options.Updated = true
 
 	return c.client.WaitForClusterReady(ctx, options.Name, options.Namespace, c.timeout)
 }
@@ -39,11 +39,20 @@ type Operations interface {
 	// Create creates a new cluster.
 	Create(ctx context.Context, options *CreateOptions) error
 
+	// Apply applies the desired state in CreateOptions to an existing cluster.
+	Apply(ctx context.Context, options *CreateOptions) error
+
 	// Delete deletes a specific cluster.
 	Delete(ctx context.Context, options *DeleteOptions) error
 
 	// Connect connects to a specific cluster.
 	Connect(ctx context.Context, options *ConnectOptions) error
+
+	// Backup archives the data and metadata of a specific cluster.
+	Backup(ctx context.Context, options *BackupOptions) error
+
+	// Restore restores the data of current cluster from a backup, the cluster should be started by Create after it.
+	Restore(ctx context.Context, options *RestoreOptions) error
 }
 
 type GetOptions struct {
@@ -64,6 +73,9 @@ type ScaleOptions struct {
 	Namespace     string
 	Name          string
 	ComponentType greptimedbclusterv1alpha1.ComponentKind
+
+	// Updated is set if the new replicas have been updated to the cluster, even if the cluster isn't ready.
+	Updated bool
 }
 
 type DeleteOptions struct {
@@ -72,6 +84,21 @@ type DeleteOptions struct {
 	TearDownEtcd bool
 }
 
+type BackupOptions struct {
+	Namespace string
+	Name      string
+
+	// Output is the path of the backup file.
+	Output string
+}
+
+type RestoreOptions struct {
+	Name string
+
+	// Input is the path of the backup file.
+	Input string
+}
+
 type CreateOptions struct {
 	Namespace string
 	Name      string
@@ -87,32 +114,71 @@ type CreateOptions struct {
 type CreateClusterOptions struct {
 	GreptimeDBChartVersion string
 	UseGreptimeCNArtifacts bool
-	ValuesFile             string
+
+	// GreptimeDBChartPath is the path of local chart directory or packaged chart, it takes precedence over GreptimeDBChartVersion.
+	GreptimeDBChartPath string
+
+	// ValuesFiles is the paths of values files, the latter will override the former.
+	ValuesFiles []string
+
+	// Values is the inline values that will override the values in ValuesFiles.
+	Values map[string]interface{}
 
 	ImageRegistry               string `helm:"image.registry"`
 	InitializerImageRegistry    string `helm:"initializer.registry"`
 	DatanodeStorageClassName    string `helm:"datanode.storage.storageClassName"`
 	DatanodeStorageSize         string `helm:"datanode.storage.storageSize"`
 	DatanodeStorageRetainPolicy string `helm:"datanode.storage.storageRetainPolicy"`
-	EtcdEndPoints               string `helm:"meta.etcdEndpoints"`
-	ConfigValues                string `helm:"*"`
+
+	// EtcdEndPoints is the endpoints of etcd, it will point to the etcd cluster created along with the cluster if it's empty.
+	EtcdEndPoints string `helm:"meta.etcdEndpoints"`
+
+	ConfigValues       string `helm:"*"`
+	StringConfigValues string `helm:"*string"`
+	FileConfigValues   string `helm:"*file"`
+	JSONConfigValues   string `helm:"*json"`
 }
 
 // CreateOperatorOptions is the options to create a GreptimeDB operator.
 type CreateOperatorOptions struct {
 	GreptimeDBOperatorChartVersion string
 	UseGreptimeCNArtifacts         bool
-	ValuesFile                     string
 
-	ImageRegistry string `helm:"image.registry"`
-	ConfigValues  string `helm:"*"`
+	// GreptimeDBOperatorChartPath is the path of local chart directory or packaged chart, it takes precedence over GreptimeDBOperatorChartVersion.
+	GreptimeDBOperatorChartPath string
+
+	// Namespace is the namespace to deploy the operator, the namespace of cluster will be used if it's empty.
+	Namespace string
+
+	// ValuesFiles is the paths of values files, the latter will override the former.
+	ValuesFiles []string
+
+	// Values is the inline values that will override the values in ValuesFiles.
+	Values map[string]interface{}
+
+	ImageRegistry      string `helm:"image.registry"`
+	ConfigValues       string `helm:"*"`
+	StringConfigValues string `helm:"*string"`
+	FileConfigValues   string `helm:"*file"`
+	JSONConfigValues   string `helm:"*json"`
 }
 
 // CreateEtcdOptions is the options to create an etcd cluster.
 type CreateEtcdOptions struct {
 	EtcdChartVersion       string
 	UseGreptimeCNArtifacts bool
-	ValuesFile             string
+
+	// EtcdChartPath is the path of local chart directory or packaged chart, it takes precedence over EtcdChartVersion.
+	EtcdChartPath string
+
+	// Namespace is the namespace to deploy the etcd cluster, the namespace of cluster will be used if it's empty.
+	Namespace string
+
+	// ValuesFiles is the paths of values files, the latter will override the former.
+	ValuesFiles []string
+
+	// Values is the inline values that will override the values in ValuesFiles.
+	Values map[string]interface{}
 
 	// The parameters reference: https://artifacthub.io/packages/helm/bitnami/etcd.
 	EtcdClusterSize      string `helm:"replicaCount"`
@@ -120,6 +186,9 @@ type CreateEtcdOptions struct {
 	EtcdStorageClassName string `helm:"persistence.storageClass"`
 	EtcdStorageSize      string `helm:"persistence.size"`
 	ConfigValues         string `helm:"*"`
+	StringConfigValues   string `helm:"*string"`
+	FileConfigValues     string `helm:"*file"`
+	JSONConfigValues     string `helm:"*json"`
 }
 
 type ConnectProtocol int
@@ -64,7 +64,7 @@ func (d *datanode) Name() string {
 	return string(greptimev1alpha1.DatanodeComponentKind)
 }
 
-func (d *datanode) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
+func (d *datanode) Start(ctx context.Context, onExit ExitHandler, binary, version string) error {
 	for i := 0; i < d.config.Replicas; i++ {
 		dirName := fmt.Sprintf("%s.%d", d.Name(), i)
 
@@ -93,13 +93,18 @@ func (d *datanode) Start(ctx context.Context, stop context.CancelFunc, binary st
 		d.dataDirs = append(d.dataDirs, path.Join(d.workingDirs.DataDir, dirName))
 
 		option := &RunOptions{
-			Binary: binary,
-			Name:   dirName,
-			logDir: datanodeLogDir,
-			pidDir: datanodePidDir,
-			args:   d.BuildArgs(i, walDir, homeDir),
+			Binary:  binary,
+			Name:    dirName,
+			Version: version,
+			logDir:  datanodeLogDir,
+			pidDir:  datanodePidDir,
+			args:    d.BuildArgs(i, walDir, homeDir),
+			addresses: ListenAddrs(map[string]string{
+				"http": d.config.HTTPAddr,
+				"rpc":  d.config.RPCAddr,
+			}, i),
 		}
-		if err := runBinary(ctx, stop, option, d.wg, d.logger); err != nil {
+		if err := runBinary(ctx, onExit, option, d.wg, d.logger); err != nil {
 			return err
 		}
 	}
@@ -45,7 +45,7 @@ func (e *etcd) Name() string {
 	return "etcd"
 }
 
-func (e *etcd) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
+func (e *etcd) Start(ctx context.Context, onExit ExitHandler, binary, version string) error {
 	var (
 		etcdDataDir = path.Join(e.workingDirs.DataDir, e.Name())
 		etcdLogDir  = path.Join(e.workingDirs.LogsDir, e.Name())
@@ -62,13 +62,20 @@ func (e *etcd) Start(ctx context.Context, stop context.CancelFunc, binary string
 	e.pidsDirs = append(e.pidsDirs, etcdPidDir)
 
 	option := &RunOptions{
-		Binary: binary,
-		Name:   e.Name(),
-		logDir: etcdLogDir,
-		pidDir: etcdPidDir,
-		args:   e.BuildArgs(etcdDataDir),
+		Binary:  binary,
+		Name:    e.Name(),
+		Version: version,
+		logDir:  etcdLogDir,
+		pidDir:  etcdPidDir,
+		args:    e.BuildArgs(etcdDataDir),
+
+		// The etcd listens on the default addresses.
+		addresses: map[string]string{
+			"client": "localhost:2379",
+			"peer":   "localhost:2380",
+		},
 	}
-	if err := runBinary(ctx, stop, option, e.wg, e.logger); err != nil {
+	if err := runBinary(ctx, onExit, option, e.wg, e.logger); err != nil {
 		return err
 	}
 
@@ -56,7 +56,7 @@ func (f *frontend) Name() string {
 	return string(greptimedbclusterv1alpha1.FrontendComponentKind)
 }
 
-func (f *frontend) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
+func (f *frontend) Start(ctx context.Context, onExit ExitHandler, binary, version string) error {
 	for i := 0; i < f.config.Replicas; i++ {
 		dirName := fmt.Sprintf("%s.%d", f.Name(), i)
 
@@ -73,13 +73,21 @@ func (f *frontend) Start(ctx context.Context, stop context.CancelFunc, binary st
 		f.pidsDirs = append(f.pidsDirs, frontendPidDir)
 
 		option := &RunOptions{
-			Binary: binary,
-			Name:   dirName,
-			logDir: frontendLogDir,
-			pidDir: frontendPidDir,
-			args:   f.BuildArgs(i),
+			Binary:  binary,
+			Name:    dirName,
+			Version: version,
+			logDir:  frontendLogDir,
+			pidDir:  frontendPidDir,
+			args:    f.BuildArgs(i),
+			addresses: ListenAddrs(map[string]string{
+				"http":     f.config.HTTPAddr,
+				"grpc":     f.config.GRPCAddr,
+				"mysql":    f.config.MysqlAddr,
+				"postgres": f.config.PostgresAddr,
+				"opentsdb": f.config.OpentsdbAddr,
+			}, i),
 		}
-		if err := runBinary(ctx, stop, option, f.wg, f.logger); err != nil {
+		if err := runBinary(ctx, onExit, option, f.wg, f.logger); err != nil {
 			return err
 		}
 	}
@@ -30,6 +30,9 @@ import (
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
+// DefaultMetaSrvBindAddr is the bind address of meta srv if it's not set in config.
+const DefaultMetaSrvBindAddr = "127.0.0.1:3002"
+
 type metaSrv struct {
 	config *config.MetaSrv
 
@@ -54,9 +57,8 @@ func (m *metaSrv) Name() string {
 	return "metasrv"
 }
 
-func (m *metaSrv) Start(ctx context.Context, stop context.CancelFunc, binary string) error {
-	// Default bind address for meta srv.
-	bindAddr := net.JoinHostPort("127.0.0.1", "3002")
+func (m *metaSrv) Start(ctx context.Context, onExit ExitHandler, binary, version string) error {
+	bindAddr := DefaultMetaSrvBindAddr
 	if len(m.config.BindAddr) > 0 {
 		bindAddr = m.config.BindAddr
 	}
@@ -77,13 +79,18 @@ func (m *metaSrv) Start(ctx context.Context, stop context.CancelFunc, binary str
 		m.pidsDirs = append(m.pidsDirs, metaSrvPidDir)
 
 		option := &RunOptions{
-			Binary: binary,
-			Name:   dirName,
-			logDir: metaSrvLogDir,
-			pidDir: metaSrvPidDir,
-			args:   m.BuildArgs(i, bindAddr),
+			Binary:  binary,
+			Name:    dirName,
+			Version: version,
+			logDir:  metaSrvLogDir,
+			pidDir:  metaSrvPidDir,
+			args:    m.BuildArgs(i, bindAddr),
+			addresses: ListenAddrs(map[string]string{
+				"http": m.config.HTTPAddr,
+				"bind": bindAddr,
+			}, i),
 		}
-		if err := runBinary(ctx, stop, option, m.wg, m.logger); err != nil {
+		if err := runBinary(ctx, onExit, option, m.wg, m.logger); err != nil {
 			return err
 		}
 	}
@@ -25,6 +25,7 @@ import (
 	"strconv"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/GreptimeTeam/gtctl/pkg/logger"
 )
@@ -34,12 +35,18 @@ type RunOptions struct {
 	Binary string
 	Name   string
 
+	// Version is the version of binary, it's empty for the local binary.
+	Version string
+
 	pidDir string
 	logDir string
 	args   []string
+
+	// addresses are the listen addresses of the process, keyed by the protocol.
+	addresses map[string]string
 }
 
-func runBinary(ctx context.Context, stop context.CancelFunc,
+func runBinary(ctx context.Context, onExit ExitHandler,
 	option *RunOptions, wg *sync.WaitGroup, logger logger.Logger) error {
 	cmd := exec.CommandContext(ctx, option.Binary, option.args...)
 
@@ -54,29 +61,48 @@ func runBinary(ctx context.Context, stop context.CancelFunc,
 	cmd.Stdout = outputFileWriter
 	cmd.Stderr = outputFileWriter
 
+	record := &RuntimeRecord{
+		Binary:    option.Binary,
+		Version:   option.Version,
+		Args:      option.args,
+		Addresses: option.addresses,
+		LogPath:   logFile,
+	}
+	if last, err := ReadRuntimeRecord(option.pidDir); err == nil {
+		// The replica is started again in the same cluster.
+		record.RestartCount = last.RestartCount + 1
+		record.LastExitCode = last.LastExitCode
+	}
+
 	if err = cmd.Start(); err != nil {
 		return err
 	}
+	record.Pid = cmd.Process.Pid
+	record.StartTime = time.Now()
 
-	pid := strconv.Itoa(cmd.Process.Pid)
+	pid := strconv.Itoa(record.Pid)
 	logger.V(3).Infof("run '%s' binary '%s' with args: '%v', log: '%s', pid: '%s'",
 		option.Name, option.Binary, option.args, option.logDir, pid)
 
-	pidFile := path.Join(option.pidDir, "pid")
-	f, err := os.Create(pidFile)
-	if err != nil {
-		return err
-	}
-
-	_, err = f.Write([]byte(pid))
-	if err != nil {
+	if err = writeRuntimeRecord(option.pidDir, record); err != nil {
 		return err
 	}
 
+	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		wg.Add(1)
-		if err := cmd.Wait(); err != nil {
+		err := cmd.Wait()
+
+		// Record the exit code, the runtime record is still kept for the next start.
+		if cmd.ProcessState != nil {
+			exitCode := cmd.ProcessState.ExitCode()
+			record.LastExitCode = &exitCode
+			if werr := writeRuntimeRecord(option.pidDir, record); werr != nil {
+				logger.V(3).Infof("failed to record the exit of component '%s': %v", option.Name, werr)
+			}
+		}
+
+		if err != nil {
 			// Caught signal kill and interrupt error then ignore.
 			if exit, ok := err.(*exec.ExitError); ok {
 				if status, ok := exit.Sys().(syscall.WaitStatus); ok && status.Signaled() {
@@ -89,8 +115,8 @@ func runBinary(ctx context.Context, stop context.CancelFunc,
 			logger.Errorf("args: '%v'", option.args)
 			_ = outputFileWriter.Flush()
 
-			// If one component has failed, stop the whole context.
-			stop()
+			// The handler decides what to do with the cluster, e.g. stop the whole context.
+			onExit(option.Name, err)
 		}
 	}()
 
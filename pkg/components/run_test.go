@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+)
+
+func TestRunBinaryRuntimeRecord(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var (
+		wg     sync.WaitGroup
+		exited []string
+		l      = logger.New(os.Stdout, log.Level(4), logger.WithColored())
+		option = &RunOptions{
+			Binary:    "/bin/sh",
+			Name:      "frontend.0",
+			Version:   "v0.4.1",
+			pidDir:    filepath.Join(tempDir, "pids"),
+			logDir:    filepath.Join(tempDir, "logs"),
+			args:      []string{"-c", "exit 3"},
+			addresses: map[string]string{"http": "127.0.0.1:4000"},
+		}
+		onExit = func(name string, err error) {
+			exited = append(exited, name)
+		}
+	)
+	for _, dir := range []string{option.pidDir, option.logDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Run the replica twice, the second run is regarded as a restart.
+	for i := 0; i < 2; i++ {
+		if err := runBinary(context.Background(), onExit, option, &wg, l); err != nil {
+			t.Fatalf("failed to run binary: %v", err)
+		}
+		wg.Wait()
+	}
+
+	record, err := ReadRuntimeRecord(option.pidDir)
+	if err != nil {
+		t.Fatalf("failed to read runtime record: %v", err)
+	}
+	if record.Pid <= 0 || record.StartTime.IsZero() {
+		t.Errorf("unexpected pid %d or start time %s", record.Pid, record.StartTime)
+	}
+	if record.Binary != option.Binary || record.Version != option.Version || len(record.Args) != 2 {
+		t.Errorf("unexpected binary '%s', version '%s' or args '%v'", record.Binary, record.Version, record.Args)
+	}
+	if record.Addresses["http"] != "127.0.0.1:4000" || record.LogPath != filepath.Join(option.logDir, "log") {
+		t.Errorf("unexpected addresses '%v' or log path '%s'", record.Addresses, record.LogPath)
+	}
+	if record.RestartCount != 1 {
+		t.Errorf("expected restart count 1, got %d", record.RestartCount)
+	}
+	if record.LastExitCode == nil || *record.LastExitCode != 3 {
+		t.Errorf("expected last exit code 3, got %v", record.LastExitCode)
+	}
+	if len(exited) != 2 || exited[0] != option.Name {
+		t.Errorf("expected the exit of '%s' to be handled twice, got %v", option.Name, exited)
+	}
+	if record.IsAlive() {
+		t.Errorf("the exited process should not be alive")
+	}
+}
+
+func TestRuntimeRecordIsAlive(t *testing.T) {
+	executable, err := processExecutable(os.Getpid())
+	if err != nil {
+		t.Skipf("can't get the executable of process: %v", err)
+	}
+
+	tests := []struct {
+		record *RuntimeRecord
+		alive  bool
+	}{
+		{&RuntimeRecord{Pid: os.Getpid(), Binary: executable}, true},
+		// The pid is reused by another binary.
+		{&RuntimeRecord{Pid: os.Getpid(), Binary: "/path/to/greptime"}, false},
+		// The record from the pid file of old versions has no binary.
+		{&RuntimeRecord{Pid: os.Getpid()}, true},
+		{&RuntimeRecord{}, false},
+	}
+	for _, tt := range tests {
+		if alive := tt.record.IsAlive(); alive != tt.alive {
+			t.Errorf("expected alive %v of %+v, got %v", tt.alive, tt.record, alive)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package components
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// RuntimeFile is the runtime record of the process of one replica, which is in its pid directory.
+	RuntimeFile = "runtime.yaml"
+
+	// PidFile is the pid of the process of one replica written by the old versions of gtctl, which is replaced by RuntimeFile.
+	PidFile = "pid"
+)
+
+// RuntimeRecord is the runtime information of the process of one replica, e.g. 'frontend.0'.
+type RuntimeRecord struct {
+	Pid       int       `yaml:"pid"`
+	StartTime time.Time `yaml:"startTime"`
+
+	// Binary is the path of binary and Version is its version, the version is empty for the local binary.
+	Binary  string   `yaml:"binary"`
+	Version string   `yaml:"version,omitempty"`
+	Args    []string `yaml:"args"`
+
+	// Addresses are the listen addresses of the process, keyed by the protocol, e.g. 'http' and 'mysql'.
+	Addresses map[string]string `yaml:"addresses,omitempty"`
+
+	LogPath string `yaml:"logPath"`
+
+	// RestartCount is how many times the replica has been started again in the same cluster.
+	RestartCount int `yaml:"restartCount"`
+
+	// LastExitCode is the exit code of the process that exited at last, it's -1 if the process is killed by signal.
+	LastExitCode *int `yaml:"lastExitCode,omitempty"`
+}
+
+// ReadRuntimeRecord reads the runtime record in the pid directory of one replica.
+func ReadRuntimeRecord(pidDir string) (*RuntimeRecord, error) {
+	in, err := os.ReadFile(filepath.Join(pidDir, RuntimeFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var record RuntimeRecord
+	if err = yaml.Unmarshal(in, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse runtime record in '%s': %v", pidDir, err)
+	}
+
+	return &record, nil
+}
+
+// writeRuntimeRecord writes the record to the pid directory atomically, so the readers never see a partial record.
+func writeRuntimeRecord(pidDir string, record *RuntimeRecord) error {
+	out, err := yaml.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return fileutils.WriteFileAtomic(filepath.Join(pidDir, RuntimeFile), out, 0644)
+}
+
+// IsAlive returns true if the recorded process is still running. Since the pid may be reused by another process
+// after the recorded one exits, the executable of the process should also be the recorded binary.
+func (r *RuntimeRecord) IsAlive() bool {
+	if r.Pid <= 0 {
+		return false
+	}
+
+	p, err := os.FindProcess(r.Pid)
+	if err != nil {
+		return false
+	}
+	if err = p.Signal(syscall.Signal(0)); err != nil {
+		return false
+	}
+
+	executable, err := processExecutable(r.Pid)
+	if err != nil || len(r.Binary) == 0 {
+		// The process is regarded as alive if its executable can't be determined or checked.
+		return true
+	}
+
+	return executable == r.Binary
+}
+
+// processExecutable returns the executable of the process, i.e. the first argument of its command line.
+func processExecutable(pid int) (string, error) {
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil {
+		return string(bytes.SplitN(cmdline, []byte{0}, 2)[0]), nil
+	}
+
+	// There is no procfs in macOS.
+	out, err := exec.Command("ps", "-o", "comm=", "-p", fmt.Sprint(pid)).Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -38,10 +38,14 @@ type allocatedDirs struct {
 	pidsDirs []string
 }
 
+// ExitHandler handles the unexpected exit of one process of component, e.g. 'frontend.0', whose error is err.
+type ExitHandler func(name string, err error)
+
 // ClusterComponent is the basic component of running GreptimeDB Cluster in bare-metal mode.
 type ClusterComponent interface {
-	// Start starts cluster component by executing binary.
-	Start(ctx context.Context, stop context.CancelFunc, binary string) error
+	// Start starts cluster component by executing binary whose version is version, the version is empty for the local binary.
+	// The onExit is called if any process of it exits unexpectedly.
+	Start(ctx context.Context, onExit ExitHandler, binary, version string) error
 
 	// BuildArgs build up args for cluster component.
 	BuildArgs(params ...interface{}) []string
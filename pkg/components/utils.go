@@ -48,3 +48,14 @@ func GenerateAddrArg(config string, addr string, nodeId int, args []string) []st
 
 	return append(args, fmt.Sprintf("%s=%s", config, socketAddr))
 }
+
+// ListenAddrs formats the listen addresses of one replica that keyed by the protocol, the empty addresses are skipped.
+func ListenAddrs(addrs map[string]string, nodeId int) map[string]string {
+	ret := make(map[string]string)
+	for protocol, addr := range addrs {
+		if socketAddr := FormatAddrArg(addr, nodeId); len(socketAddr) > 0 {
+			ret[protocol] = socketAddr
+		}
+	}
+	return ret
+}
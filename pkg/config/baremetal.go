@@ -54,6 +54,7 @@ type Artifact struct {
 
 	// Version is the release version of binary(greptime or etcd).
 	// Usually, it points to the version of binary of GitHub release.
+	// It can also be the OCI reference of binary, e.g. 'oci://registry.example.com/greptime:v0.4.0'.
 	Version string `yaml:"version"`
 }
 
@@ -28,41 +28,80 @@ const (
 	configEtcd     = "etcd"
 )
 
+// SetValues is the values that set in command line. It follows the grammar of Helm,
+// and each value can be routed to the operator, cluster or etcd chart by its prefix,
+// the value without prefix will be routed to the cluster chart.
 type SetValues struct {
+	// RawConfig is the values of '--set'.
 	RawConfig []string
 
+	// RawStringConfig is the values of '--set-string', the values are always parsed as string.
+	RawStringConfig []string
+
+	// RawFileConfig is the values of '--set-file', the values are the paths of files that hold the real values.
+	RawFileConfig []string
+
+	// RawJSONConfig is the values of '--set-json', the values are parsed as JSON.
+	RawJSONConfig []string
+
 	OperatorConfig string
 	ClusterConfig  string
 	EtcdConfig     string
+
+	OperatorStringConfig string
+	ClusterStringConfig  string
+	EtcdStringConfig     string
+
+	OperatorFileConfig string
+	ClusterFileConfig  string
+	EtcdFileConfig     string
+
+	OperatorJSONConfig string
+	ClusterJSONConfig  string
+	EtcdJSONConfig     string
 }
 
 // Parse parses raw config values and classify it to different
 // categories of config type by its prefix.
 func (c *SetValues) Parse() error {
+	var err error
+
+	if c.OperatorConfig, c.ClusterConfig, c.EtcdConfig, err = parseSetValues(c.RawConfig, false); err != nil {
+		return err
+	}
+	if c.OperatorStringConfig, c.ClusterStringConfig, c.EtcdStringConfig, err = parseSetValues(c.RawStringConfig, false); err != nil {
+		return err
+	}
+	if c.OperatorFileConfig, c.ClusterFileConfig, c.EtcdFileConfig, err = parseSetValues(c.RawFileConfig, false); err != nil {
+		return err
+	}
+	if c.OperatorJSONConfig, c.ClusterJSONConfig, c.EtcdJSONConfig, err = parseSetValues(c.RawJSONConfig, true); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseSetValues splits the raw values into 'key=value' pairs and joins the pairs of each config type.
+func parseSetValues(rawValues []string, isJSON bool) (string, string, string, error) {
 	var (
 		operatorConfig []string
 		clusterConfig  []string
 		etcdConfig     []string
 	)
 
-	for _, raw := range c.RawConfig {
+	for _, raw := range rawValues {
 		if len(raw) == 0 {
-			return fmt.Errorf("cannot parse empty config values")
+			return "", "", "", fmt.Errorf("cannot parse empty config values")
 		}
 
-		var configPrefix, configValue string
-		values := strings.Split(raw, ",")
-
-		for _, value := range values {
-			value = strings.Trim(value, " ")
-			cfg := strings.SplitN(value, ".", 2)
-			configPrefix = cfg[0]
-			if len(cfg) == 2 {
-				configValue = cfg[1]
-			} else {
-				configValue = configPrefix
-			}
+		pairs, err := splitPairs(raw, isJSON)
+		if err != nil {
+			return "", "", "", fmt.Errorf("cannot parse config values '%s': %v", raw, err)
+		}
 
+		for _, pair := range pairs {
+			configPrefix, configValue := splitPrefix(pair)
 			switch configPrefix {
 			case configOperator:
 				operatorConfig = append(operatorConfig, configValue)
@@ -71,20 +110,86 @@ func (c *SetValues) Parse() error {
 			case configEtcd:
 				etcdConfig = append(etcdConfig, configValue)
 			default:
-				clusterConfig = append(clusterConfig, value)
+				clusterConfig = append(clusterConfig, pair)
 			}
 		}
 	}
 
-	if len(operatorConfig) > 0 {
-		c.OperatorConfig = strings.Join(operatorConfig, ",")
+	return strings.Join(operatorConfig, ","), strings.Join(clusterConfig, ","), strings.Join(etcdConfig, ","), nil
+}
+
+// splitPairs splits the raw value into 'key=value' pairs by the commas that are not escaped
+// and not in a list like '{a,b}'. If isJSON is true, the commas in JSON objects, arrays and
+// strings will be kept.
+func splitPairs(raw string, isJSON bool) ([]string, error) {
+	var (
+		pairs    []string
+		current  []rune
+		depth    int
+		inString bool
+		escaped  bool
+		prev     rune
+	)
+
+	flush := func() {
+		if pair := strings.TrimSpace(string(current)); len(pair) > 0 {
+			pairs = append(pairs, pair)
+		}
+		current = current[:0]
 	}
-	if len(clusterConfig) > 0 {
-		c.ClusterConfig = strings.Join(clusterConfig, ",")
+
+	for _, r := range raw {
+		current = append(current, r)
+
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case isJSON && r == '"':
+			inString = !inString
+		case inString:
+		case isJSON && (r == '{' || r == '['):
+			depth++
+		case isJSON && (r == '}' || r == ']'):
+			depth--
+		case !isJSON && r == '{' && prev == '=':
+			// The list value of Helm like 'key={a,b}'.
+			depth++
+		case !isJSON && r == '}' && depth > 0:
+			depth--
+		case r == ',' && depth == 0:
+			current = current[:len(current)-1]
+			flush()
+		}
+
+		prev = r
 	}
-	if len(etcdConfig) > 0 {
-		c.EtcdConfig = strings.Join(etcdConfig, ",")
+
+	if depth != 0 || inString {
+		return nil, fmt.Errorf("unclosed list, object or string")
 	}
+	flush()
 
-	return nil
+	return pairs, nil
+}
+
+// splitPrefix splits the config type prefix from the key of pair.
+// For example, 'cluster.a.b=c' will be split into 'cluster' and 'a.b=c'.
+func splitPrefix(pair string) (string, string) {
+	escaped := false
+	for i, r := range pair {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			return pair[:i], pair[i+1:]
+		case r == '=' || r == '[':
+			// The key has no prefix.
+			return "", pair
+		}
+	}
+	return "", pair
 }
@@ -53,6 +53,20 @@ func TestParseConfig(t *testing.T) {
 				EtcdConfig:    "foo=bar",
 			},
 		},
+		{
+			name:   "helm-grammar",
+			config: []string{`cluster.tags={a,b},etcd.auth.token=a\,b,operator.annotations.foo\.io/bar=baz`, "list[0].name=foo"},
+			expect: SetValues{
+				ClusterConfig:  "tags={a,b},list[0].name=foo",
+				EtcdConfig:     `auth.token=a\,b`,
+				OperatorConfig: `annotations.foo\.io/bar=baz`,
+			},
+		},
+		{
+			name:   "unclosed-list",
+			config: []string{"cluster.tags={a,b"},
+			err:    true,
+		},
 		{
 			name:   "empty-values",
 			config: []string{""},
@@ -80,3 +94,22 @@ func TestParseConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTypedConfig(t *testing.T) {
+	actual := SetValues{
+		RawStringConfig: []string{"cluster.image.tag=0123,etcd.replicaCount=3"},
+		RawFileConfig:   []string{"operator.config=/tmp/config.toml"},
+		RawJSONConfig:   []string{`cluster.resources={"limits":{"cpu":"1","memory":"1Gi"}},etcd.tolerations=[{"key":"a","operator":"Exists"}]`},
+	}
+	assert.NoError(t, actual.Parse())
+
+	assert.Equal(t, "image.tag=0123", actual.ClusterStringConfig)
+	assert.Equal(t, "replicaCount=3", actual.EtcdStringConfig)
+	assert.Equal(t, "config=/tmp/config.toml", actual.OperatorFileConfig)
+	assert.Equal(t, `resources={"limits":{"cpu":"1","memory":"1Gi"}}`, actual.ClusterJSONConfig)
+	assert.Equal(t, `tolerations=[{"key":"a","operator":"Exists"}]`, actual.EtcdJSONConfig)
+	assert.Empty(t, actual.ClusterConfig)
+
+	invalid := SetValues{RawJSONConfig: []string{`cluster.resources={"limits":"1}`}}
+	assert.Error(t, invalid.Parse())
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ClusterSpecAPIVersion is the current version of the declarative cluster spec.
+	ClusterSpecAPIVersion = "gtctl.greptime.io/v1alpha1"
+
+	// ClusterSpecKind is the kind of the declarative cluster spec.
+	ClusterSpecKind = "GreptimeDBCluster"
+)
+
+// KubernetesClusterSpec is the declarative spec of a GreptimeDB cluster in Kubernetes.
+// It covers all the settings of the operator, etcd and cluster charts, so that
+// the cluster definition can be kept in a single versioned file.
+type KubernetesClusterSpec struct {
+	APIVersion string                     `yaml:"apiVersion" validate:"required"`
+	Kind       string                     `yaml:"kind" validate:"required"`
+	Metadata   *SpecMetadata              `yaml:"metadata" validate:"required"`
+	Spec       *KubernetesClusterSpecBody `yaml:"spec" validate:"required"`
+}
+
+type SpecMetadata struct {
+	Name      string `yaml:"name" validate:"required"`
+	Namespace string `yaml:"namespace"`
+}
+
+type KubernetesClusterSpecBody struct {
+	// ImageRegistry is the image registry of all the charts, it can be overridden by each chart.
+	ImageRegistry string `yaml:"imageRegistry"`
+
+	// UseGreptimeCNArtifacts indicates whether to use the artifacts(charts and images) from CN region.
+	UseGreptimeCNArtifacts bool `yaml:"useGreptimeCNArtifacts"`
+
+	Operator *OperatorSpec          `yaml:"operator" validate:"required"`
+	Etcd     *EtcdSpec              `yaml:"etcd" validate:"required"`
+	Cluster  *GreptimeDBClusterSpec `yaml:"cluster" validate:"required"`
+}
+
+type OperatorSpec struct {
+	Namespace     string                 `yaml:"namespace"`
+	ChartVersion  string                 `yaml:"chartVersion"`
+	Chart         string                 `yaml:"chart"`
+	ImageRegistry string                 `yaml:"imageRegistry"`
+	ValuesFiles   []string               `yaml:"valuesFiles" validate:"dive,filepath"`
+	Values        map[string]interface{} `yaml:"values"`
+}
+
+type EtcdSpec struct {
+	Namespace        string                 `yaml:"namespace"`
+	ChartVersion     string                 `yaml:"chartVersion"`
+	Chart            string                 `yaml:"chart"`
+	ImageRegistry    string                 `yaml:"imageRegistry"`
+	StorageClassName string                 `yaml:"storageClassName"`
+	StorageSize      string                 `yaml:"storageSize"`
+	ClusterSize      string                 `yaml:"clusterSize"`
+	ValuesFiles      []string               `yaml:"valuesFiles" validate:"dive,filepath"`
+	Values           map[string]interface{} `yaml:"values"`
+}
+
+type GreptimeDBClusterSpec struct {
+	ChartVersion        string                 `yaml:"chartVersion"`
+	Chart               string                 `yaml:"chart"`
+	ImageRegistry       string                 `yaml:"imageRegistry"`
+	StorageClassName    string                 `yaml:"storageClassName"`
+	StorageSize         string                 `yaml:"storageSize"`
+	StorageRetainPolicy string                 `yaml:"storageRetainPolicy"`
+	ValuesFiles         []string               `yaml:"valuesFiles" validate:"dive,filepath"`
+	Values              map[string]interface{} `yaml:"values"`
+}
+
+// DefaultKubernetesClusterSpec returns the spec that has the same defaults as the command line flags.
+func DefaultKubernetesClusterSpec() *KubernetesClusterSpec {
+	return &KubernetesClusterSpec{
+		APIVersion: ClusterSpecAPIVersion,
+		Kind:       ClusterSpecKind,
+		Metadata: &SpecMetadata{
+			Namespace: "default",
+		},
+		Spec: &KubernetesClusterSpecBody{
+			Operator: &OperatorSpec{
+				Namespace: "default",
+			},
+			Etcd: &EtcdSpec{
+				Namespace:        "default",
+				StorageClassName: "null",
+				StorageSize:      "10Gi",
+				ClusterSize:      "1",
+			},
+			Cluster: &GreptimeDBClusterSpec{
+				StorageClassName:    "null",
+				StorageSize:         "10Gi",
+				StorageRetainPolicy: "Retain",
+			},
+		},
+	}
+}
+
+// LoadKubernetesClusterSpec loads the spec from file, the fields that not set in file will use the default values.
+func LoadKubernetesClusterSpec(path string) (*KubernetesClusterSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := DefaultKubernetesClusterSpec()
+	if err = yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("invalid cluster spec '%s': %v", path, err)
+	}
+
+	if err = ValidateKubernetesClusterSpec(spec); err != nil {
+		return nil, err
+	}
+
+	// The values files in spec are relative to the spec file rather than the current directory.
+	dir := filepath.Dir(path)
+	resolveFiles(dir, spec.Spec.Operator.ValuesFiles)
+	resolveFiles(dir, spec.Spec.Etcd.ValuesFiles)
+	resolveFiles(dir, spec.Spec.Cluster.ValuesFiles)
+
+	return spec, nil
+}
+
+// resolveFiles resolves the relative files against dir in place.
+func resolveFiles(dir string, files []string) {
+	for i, file := range files {
+		if !filepath.IsAbs(file) {
+			files[i] = filepath.Join(dir, file)
+		}
+	}
+}
+
+// ValidateKubernetesClusterSpec validates the spec of cluster in Kubernetes.
+func ValidateKubernetesClusterSpec(spec *KubernetesClusterSpec) error {
+	if spec == nil {
+		return fmt.Errorf("no spec to validate")
+	}
+
+	if err := validator.New().Struct(spec); err != nil {
+		return err
+	}
+
+	if spec.APIVersion != ClusterSpecAPIVersion {
+		return fmt.Errorf("unsupported apiVersion '%s', expected '%s'", spec.APIVersion, ClusterSpecAPIVersion)
+	}
+	if spec.Kind != ClusterSpecKind {
+		return fmt.Errorf("unsupported kind '%s', expected '%s'", spec.Kind, ClusterSpecKind)
+	}
+
+	return nil
+}
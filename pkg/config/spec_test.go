@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadKubernetesClusterSpec(t *testing.T) {
+	spec, err := LoadKubernetesClusterSpec(filepath.Join("testdata", "spec", "full_spec.yaml"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "mycluster", spec.Metadata.Name)
+	assert.Equal(t, "greptimedb", spec.Metadata.Namespace)
+	assert.Equal(t, "registry.example.com", spec.Spec.ImageRegistry)
+
+	// The fields that set in file.
+	assert.Equal(t, "0.1.1-alpha.12", spec.Spec.Operator.ChartVersion)
+	assert.Equal(t, "./charts/greptimedb-operator", spec.Spec.Operator.Chart)
+	assert.Equal(t, "20Gi", spec.Spec.Etcd.StorageSize)
+	assert.Equal(t, "3", spec.Spec.Etcd.ClusterSize)
+	assert.Equal(t, "ebs-sc", spec.Spec.Cluster.StorageClassName)
+	assert.Equal(t, map[string]interface{}{"frontend": map[string]interface{}{"replicas": 3}}, spec.Spec.Cluster.Values)
+
+	// The relative values files are resolved against the directory of spec file.
+	assert.Equal(t, []string{filepath.Join("testdata", "spec", "values", "cluster.yaml")}, spec.Spec.Cluster.ValuesFiles)
+	assert.Equal(t, []string{"/etc/gtctl/etcd-values.yaml"}, spec.Spec.Etcd.ValuesFiles)
+
+	// The fields that not set in file should keep the defaults.
+	assert.Equal(t, "default", spec.Spec.Operator.Namespace)
+	assert.Equal(t, "null", spec.Spec.Etcd.StorageClassName)
+	assert.Equal(t, "10Gi", spec.Spec.Cluster.StorageSize)
+	assert.Equal(t, "Retain", spec.Spec.Cluster.StorageRetainPolicy)
+}
+
+func TestLoadInvalidKubernetesClusterSpec(t *testing.T) {
+	testCases := []struct {
+		name   string
+		errKey string
+	}{
+		{
+			name:   "invalid_kind",
+			errKey: "unsupported kind",
+		},
+		{
+			name:   "missing_name",
+			errKey: "KubernetesClusterSpec.Metadata.Name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadKubernetesClusterSpec(filepath.Join("testdata", "spec", tc.name+".yaml"))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errKey)
+		})
+	}
+}
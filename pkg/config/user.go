@@ -0,0 +1,187 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// UserConfigFile is the file name of the user config and it's stored in the working directory of gtctl.
+	UserConfigFile = "config.yaml"
+
+	// ModeKubernetes indicates the cluster is deployed in Kubernetes.
+	ModeKubernetes = "k8s"
+
+	// ModeBareMetal indicates the cluster is deployed on bare-metal.
+	ModeBareMetal = "bare-metal"
+
+	// ArtifactRegionGlobal indicates the artifacts are downloaded from GitHub and docker.io.
+	ArtifactRegionGlobal = "global"
+
+	// ArtifactRegionCN indicates the artifacts are downloaded from 'downloads.greptime.cn'.
+	ArtifactRegionCN = "cn"
+)
+
+// UserConfig is the config of gtctl user, it holds the named contexts.
+type UserConfig struct {
+	// CurrentContext is the name of the context that commands use by default.
+	CurrentContext string `yaml:"currentContext"`
+
+	// GreptimeVersion is the default version of greptime binary that set by 'gtctl greptime use'.
+	// The version in context takes precedence over it.
+	GreptimeVersion string `yaml:"greptimeVersion,omitempty"`
+
+	Contexts []*Context `yaml:"contexts" validate:"dive"`
+}
+
+// Context is a named group of the default settings of gtctl commands.
+// The flags that set in command line will still override the settings of context.
+type Context struct {
+	Name string `yaml:"name" validate:"required"`
+
+	// Mode is the deployment mode of clusters, can be 'k8s' or 'bare-metal'.
+	Mode string `yaml:"mode,omitempty" validate:"omitempty,oneof=k8s bare-metal"`
+
+	// Kubeconfig is the path of kubeconfig, use '~/.kube/config' if it's empty.
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+
+	// KubeContext is the context in kubeconfig, use the current context of kubeconfig if it's empty.
+	KubeContext string `yaml:"kubeContext,omitempty"`
+
+	// Namespace is the default namespace of clusters in Kubernetes.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// ImageRegistry is the image registry of charts.
+	ImageRegistry string `yaml:"imageRegistry,omitempty"`
+
+	// ArtifactRegion is the region to download artifacts, can be 'global' or 'cn'.
+	ArtifactRegion string `yaml:"artifactRegion,omitempty" validate:"omitempty,oneof=global cn"`
+
+	// The default versions of artifacts.
+	GreptimeVersion           string `yaml:"greptimeVersion,omitempty"`
+	GreptimeDBChartVersion    string `yaml:"greptimedbChartVersion,omitempty"`
+	GreptimeDBOperatorVersion string `yaml:"greptimedbOperatorChartVersion,omitempty"`
+	EtcdChartVersion          string `yaml:"etcdChartVersion,omitempty"`
+}
+
+// LoadUserConfig loads the user config from path. It returns an empty config if the file does not exist.
+func LoadUserConfig(path string) (*UserConfig, error) {
+	cfg := &UserConfig{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid user config '%s': %v", path, err)
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Save saves the user config to path.
+func (c *UserConfig) Save(path string) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err = fileutils.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, out, 0644)
+}
+
+// Validate validates the user config.
+func (c *UserConfig) Validate() error {
+	if err := validator.New().Struct(c); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, ctx := range c.Contexts {
+		if names[ctx.Name] {
+			return fmt.Errorf("duplicated context '%s'", ctx.Name)
+		}
+		names[ctx.Name] = true
+	}
+
+	if len(c.CurrentContext) > 0 && !names[c.CurrentContext] {
+		return fmt.Errorf("current context '%s' is not found", c.CurrentContext)
+	}
+
+	return nil
+}
+
+// GetContext returns the context by name, or nil if it does not exist.
+func (c *UserConfig) GetContext(name string) *Context {
+	for _, ctx := range c.Contexts {
+		if ctx.Name == name {
+			return ctx
+		}
+	}
+	return nil
+}
+
+// GetCurrentContext returns the current context, or nil if it's not set.
+func (c *UserConfig) GetCurrentContext() *Context {
+	if len(c.CurrentContext) == 0 {
+		return nil
+	}
+	return c.GetContext(c.CurrentContext)
+}
+
+// SetContext adds the context or replaces the context that has the same name.
+func (c *UserConfig) SetContext(ctx *Context) {
+	for i, old := range c.Contexts {
+		if old.Name == ctx.Name {
+			c.Contexts[i] = ctx
+			return
+		}
+	}
+	c.Contexts = append(c.Contexts, ctx)
+}
+
+// UseContext sets the current context.
+func (c *UserConfig) UseContext(name string) error {
+	if c.GetContext(name) == nil {
+		return fmt.Errorf("context '%s' is not found", name)
+	}
+	c.CurrentContext = name
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserConfig(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, UserConfigFile)
+
+	// Load the config that does not exist.
+	cfg, err := LoadUserConfig(path)
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.GetCurrentContext())
+
+	cfg.SetContext(&Context{Name: "dev", Mode: ModeBareMetal, GreptimeVersion: "v0.4.0"})
+	cfg.SetContext(&Context{Name: "prod", Mode: ModeKubernetes, Namespace: "greptimedb", ArtifactRegion: ArtifactRegionCN})
+	assert.Error(t, cfg.UseContext("not-exist"))
+	assert.NoError(t, cfg.UseContext("prod"))
+	assert.NoError(t, cfg.Save(path))
+
+	actual, err := LoadUserConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, actual)
+	assert.Equal(t, "greptimedb", actual.GetCurrentContext().Namespace)
+
+	// Replace the existing context.
+	actual.SetContext(&Context{Name: "prod", Mode: ModeKubernetes})
+	assert.Len(t, actual.Contexts, 2)
+	assert.Empty(t, actual.GetCurrentContext().Namespace)
+}
+
+func TestValidateUserConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  *UserConfig
+	}{
+		{
+			name: "duplicated_context",
+			cfg:  &UserConfig{Contexts: []*Context{{Name: "a"}, {Name: "a"}}},
+		},
+		{
+			name: "invalid_mode",
+			cfg:  &UserConfig{Contexts: []*Context{{Name: "a", Mode: "docker"}}},
+		},
+		{
+			name: "current_context_not_found",
+			cfg:  &UserConfig{CurrentContext: "b", Contexts: []*Context{{Name: "a"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Error(t, tc.cfg.Validate())
+		})
+	}
+}
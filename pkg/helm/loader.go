@@ -49,8 +49,14 @@ type Loader struct {
 	// am is the artifacts manager to manage charts.
 	am artifacts.Manager
 
+	// offlineAM is the artifacts manager that only uses the cached charts.
+	offlineAM artifacts.Manager
+
 	// mm is the metadata manager to manage the metadata.
 	mm metadata.Manager
+
+	// artifactsOptions is the options to create the artifacts manager.
+	artifactsOptions []artifacts.Option
 }
 
 type Option func(*Loader)
@@ -58,21 +64,31 @@ type Option func(*Loader)
 func NewLoader(l logger.Logger, opts ...Option) (*Loader, error) {
 	r := &Loader{logger: l}
 
-	am, err := artifacts.NewManager(l)
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mm == nil {
+		mm, err := metadata.New("")
+		if err != nil {
+			return nil, err
+		}
+		r.mm = mm
+	}
+
+	// The charts are cached in the working directory of metadata by default.
+	artifactsOptions := append([]artifacts.Option{artifacts.WithWorkingDir(r.mm.GetWorkingDir())}, r.artifactsOptions...)
+	am, err := artifacts.NewManager(l, artifactsOptions...)
 	if err != nil {
 		return nil, err
 	}
 	r.am = am
 
-	mm, err := metadata.New("")
+	offlineAM, err := artifacts.NewManager(l, append(artifactsOptions, artifacts.WithOffline(true))...)
 	if err != nil {
 		return nil, err
 	}
-	r.mm = mm
-
-	for _, opt := range opts {
-		opt(r)
-	}
+	r.offlineAM = offlineAM
 
 	return r, nil
 }
@@ -88,6 +104,13 @@ func WithHomeDir(dir string) Option {
 	}
 }
 
+// WithArtifactsOptions sets the options of artifacts manager, such as refreshing the cached versions.
+func WithArtifactsOptions(opts ...artifacts.Option) Option {
+	return func(r *Loader) {
+		r.artifactsOptions = append(r.artifactsOptions, opts...)
+	}
+}
+
 // LoadOptions is the options for running LoadAndRenderChart.
 type LoadOptions struct {
 	// ReleaseName is the name of the release.
@@ -102,62 +125,131 @@ type LoadOptions struct {
 	// ChartVersion is the version of the chart.
 	ChartVersion string
 
+	// ChartPath is the path of local chart directory or packaged chart.
+	// If it's set, the chart is loaded from it directly without resolving the ChartVersion.
+	ChartPath string
+
 	// FromCNRegion indicates whether to use the artifacts from CN region.
 	FromCNRegion bool
 
 	// ValuesOptions is the options for generating the helm values.
 	ValuesOptions interface{}
 
-	// ValuesFile is the path to the values file.
-	ValuesFile string
+	// ValuesFiles is the paths to the values files, the latter will override the former.
+	ValuesFiles []string
+
+	// Values is the inline values that override the values in ValuesFiles.
+	Values Values
 
 	// EnableCache indicates whether to enable the cache.
 	EnableCache bool
+
+	// ValuesOptionsSources is the source of each field in ValuesOptions keyed by the field name, such as 'flag'.
+	// It's only used by LoadValues, and the field that is not in it is regarded as the default of gtctl.
+	ValuesOptionsSources map[string]string
+}
+
+// RenderedChart is the result of LoadAndRenderChart.
+type RenderedChart struct {
+	// Manifests is the manifests rendered from the chart.
+	Manifests []byte
+
+	// Version is the resolved version of the chart, for example, the version that 'latest' refers to.
+	// It's the path of chart if the chart is loaded from local.
+	Version string
 }
 
 // LoadAndRenderChart loads the chart from the remote charts and render the manifests with the values.
-func (r *Loader) LoadAndRenderChart(ctx context.Context, opts *LoadOptions) ([]byte, error) {
-	values, err := ToHelmValues(opts.ValuesOptions, opts.ValuesFile)
+func (r *Loader) LoadAndRenderChart(ctx context.Context, opts *LoadOptions) (*RenderedChart, error) {
+	values, err := toHelmValues(opts.ValuesOptions, opts.ValuesFiles, opts.Values)
 	if err != nil {
 		return nil, err
 	}
 	r.logger.V(3).Infof("create '%s' with values: %v", opts.ReleaseName, values)
 
+	helmChart, version, err := r.loadChart(ctx, r.am, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := r.generateManifests(ctx, opts.ReleaseName, opts.Namespace, helmChart, values)
+	if err != nil {
+		return nil, err
+	}
+	r.logger.V(3).Infof("create '%s' with manifests: %s", opts.ReleaseName, string(manifests))
+
+	return &RenderedChart{Manifests: manifests, Version: version}, nil
+}
+
+// LoadValues returns the merged values that LoadAndRenderChart will use and the source of each key.
+// It works offline, the default values of chart are read from the local chart or the cached one,
+// and it fails if the chart is not cached.
+func (r *Loader) LoadValues(ctx context.Context, opts *LoadOptions) (*SourcedValues, error) {
+	helmChart, _, err := r.loadChart(ctx, r.offlineAM, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the default values of chart '%s' offline: %v, create the cluster to cache the chart or use the local chart", opts.ChartName, err)
+	}
+
+	return toSourcedValues(helmChart.Values, opts.ValuesOptions, opts.ValuesOptionsSources, opts.ValuesFiles, opts.Values)
+}
+
+// loadChart loads the chart from the local path, or the cache and the remote charts by the artifacts manager.
+// It also returns the resolved version of chart, which is the path of chart if it's loaded from local.
+func (r *Loader) loadChart(ctx context.Context, am artifacts.Manager, opts *LoadOptions) (*chart.Chart, string, error) {
+	if len(opts.ChartPath) > 0 {
+		helmChart, err := r.loadLocalChart(opts.ChartPath)
+		return helmChart, opts.ChartPath, err
+	}
+
 	if opts.ChartVersion == "" {
 		opts.ChartVersion = artifacts.LatestVersionTag
 	}
 
-	src, err := r.am.NewSource(opts.ChartName, opts.ChartVersion, artifacts.ArtifactTypeChart, opts.FromCNRegion)
+	src, err := am.NewSource(opts.ChartName, opts.ChartVersion, artifacts.ArtifactTypeChart, opts.FromCNRegion)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	destDir, err := r.mm.AllocateArtifactFilePath(src, false)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	chartFile, err := r.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{EnableCache: opts.EnableCache})
+	chartFile, err := am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{EnableCache: opts.EnableCache})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	data, err := os.ReadFile(chartFile)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+
 	helmChart, err := loader.LoadArchive(bytes.NewReader(data))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	manifests, err := r.generateManifests(ctx, opts.ReleaseName, opts.Namespace, helmChart, values)
+	return helmChart, src.Version, nil
+}
+
+// loadLocalChart loads the chart from local directory or packaged chart.
+// The dependencies of chart should be in its 'charts/' directory, like 'helm install' does.
+func (r *Loader) loadLocalChart(path string) (*chart.Chart, error) {
+	r.logger.V(3).Infof("Loading chart from '%s'", path)
+
+	helmChart, err := loader.Load(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to load chart '%s': %v", path, err)
+	}
+
+	if deps := helmChart.Metadata.Dependencies; len(deps) > 0 {
+		if err := action.CheckDependencies(helmChart, deps); err != nil {
+			return nil, fmt.Errorf("invalid chart '%s': %v, run 'helm dependency build %s' to update the 'charts/' directory", path, err, path)
+		}
 	}
-	r.logger.V(3).Infof("create '%s' with manifests: %s", opts.ReleaseName, string(manifests))
 
-	return manifests, nil
+	return helmChart, nil
 }
 
 func (r *Loader) generateManifests(ctx context.Context, releaseName, namespace string, chart *chart.Chart, values map[string]interface{}) ([]byte, error) {
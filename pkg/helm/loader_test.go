@@ -19,6 +19,8 @@ package helm
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/kind/pkg/log"
@@ -54,15 +56,20 @@ func TestLoadAndRenderChart(t *testing.T) {
 			InitializerImageRegistry:    "registry.cn-hangzhou.aliyuncs.com",
 			ConfigValues:                "meta.replicas=3",
 		},
-		ValuesFile:  "./testdata/db-values.yaml",
+		ValuesFiles: []string{"./testdata/db-values.yaml"},
 		EnableCache: false,
 	}
 
 	ctx := context.Background()
-	manifests, err := r.LoadAndRenderChart(ctx, opts)
+	rendered, err := r.LoadAndRenderChart(ctx, opts)
 	if err != nil {
 		t.Fatalf("failed to load and render chart: %v", err)
 	}
+	manifests := rendered.Manifests
+
+	if rendered.Version != "0.1.2" {
+		t.Errorf("expected version '0.1.2', got '%s'", rendered.Version)
+	}
 
 	wantedManifests, err := os.ReadFile("./testdata/db-manifests.yaml")
 	if err != nil {
@@ -74,6 +81,65 @@ func TestLoadAndRenderChart(t *testing.T) {
 	}
 }
 
+func TestLoadAndRenderLocalChart(t *testing.T) {
+	r, err := NewLoader(logger.New(os.Stdout, log.Level(4), logger.WithColored()), WithHomeDir(testMetadataDir))
+	if err != nil {
+		t.Errorf("failed to create render: %v", err)
+	}
+	defer cleanMetadataDir()
+
+	opts := &LoadOptions{
+		ReleaseName: "gtctl-ut",
+		Namespace:   "default",
+		ChartName:   artifacts.GreptimeDBClusterChartName,
+		// The version is ignored because the chart is loaded from local directory.
+		ChartVersion: "0.0.0-not-exist",
+		ChartPath:    "./testdata/local-chart",
+		ValuesOptions: opt.CreateClusterOptions{
+			ConfigValues: "replicas=3",
+		},
+	}
+
+	rendered, err := r.LoadAndRenderChart(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("failed to load and render chart: %v", err)
+	}
+	manifests := rendered.Manifests
+
+	// The local chart takes the place of version.
+	if rendered.Version != opts.ChartPath {
+		t.Errorf("expected version '%s', got '%s'", opts.ChartPath, rendered.Version)
+	}
+
+	for _, wanted := range []string{"name: gtctl-ut-sub", `replicas: "3"`, "image: greptime/greptimedb"} {
+		if !strings.Contains(string(manifests), wanted) {
+			t.Errorf("expected '%s' in manifests, got %s", wanted, string(manifests))
+		}
+	}
+
+	// The chart without its dependencies in 'charts/' can't be loaded.
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, file := range []string{"Chart.yaml", "values.yaml"} {
+		data, err := os.ReadFile(filepath.Join("./testdata/local-chart", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, file), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts.ChartPath = tempDir
+	if _, err := r.LoadAndRenderChart(context.Background(), opts); err == nil {
+		t.Errorf("expected error for the chart without dependencies")
+	}
+}
+
 func cleanMetadataDir() {
 	os.RemoveAll(testMetadataDir)
 }
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// ValueSourceDefault indicates the value comes from the default values of chart or gtctl.
+	ValueSourceDefault = "default"
+
+	// ValueSourceFile indicates the value comes from the values files.
+	ValueSourceFile = "file"
+
+	// ValueSourceSpec indicates the value comes from the spec file of cluster, including its inline values.
+	ValueSourceSpec = "spec"
+
+	// ValueSourceFlag indicates the value comes from the command line flags that set by users, including '--set'.
+	ValueSourceFlag = "flag"
+)
+
+// SourcedValues is the merged helm values with the source of each key.
+type SourcedValues struct {
+	// Values is the merged values.
+	Values Values
+
+	// Sources is the source of each leaf key, the key is the path of value like 'image.tag'.
+	Sources map[string]string
+}
+
+// toSourcedValues merges the values in the same order as the chart renders them, and records the source of each key.
+// The precedence from low to high is: defaults, valuesFiles, inline and input struct. The source of each field
+// of input struct is in fieldSources, and the field that is not in it is regarded as the default of gtctl.
+func toSourcedValues(defaults Values, input interface{}, fieldSources map[string]string, valuesFiles []string, inline Values) (*SourcedValues, error) {
+	v := &SourcedValues{Values: Values{}, Sources: make(map[string]string)}
+	v.merge(defaults, ValueSourceDefault)
+
+	for _, valuesFile := range valuesFiles {
+		values, err := NewFromFile(valuesFile)
+		if err != nil {
+			return nil, err
+		}
+		v.merge(values, ValueSourceFile)
+	}
+	v.merge(inline, ValueSourceSpec)
+
+	if err := v.mergeStruct(input, fieldSources); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// OutputAnnotatedValues returns the values as a yaml byte array, each leaf key is annotated with its source.
+func (v *SourcedValues) OutputAnnotatedValues() ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(map[string]interface{}(v.Values)); err != nil {
+		return nil, err
+	}
+	v.annotate(&node, nil)
+
+	buf := bytes.NewBuffer([]byte{})
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mergeStruct merges the values of input struct field by field in the same precedence as struct2Values,
+// so that each key is recorded with the source of the field that sets it.
+func (v *SourcedValues) mergeStruct(input interface{}, fieldSources map[string]string) error {
+	valueOf := reflect.ValueOf(input)
+	if valueOf.Kind() != reflect.Struct {
+		return fmt.Errorf("invalid input type, should be struct")
+	}
+	typeOf := valueOf.Type()
+
+	// Like '--set-json', '--set', '--set-string' and '--set-file' of Helm.
+	precedence := func(tag string) int {
+		switch tag {
+		case "*json":
+			return 0
+		case "*string":
+			return 2
+		case "*file":
+			return 3
+		default:
+			return 1
+		}
+	}
+
+	for level := 0; level <= 3; level++ {
+		for i := 0; i < typeOf.NumField(); i++ {
+			field := typeOf.Field(i)
+			tag := field.Tag.Get(FieldTag)
+			if len(tag) == 0 || precedence(tag) != level {
+				continue
+			}
+
+			// Only the field itself is kept to convert its values.
+			single := reflect.New(typeOf).Elem()
+			single.Field(i).Set(valueOf.Field(i))
+			values, err := struct2Values(single.Interface())
+			if err != nil {
+				return err
+			}
+
+			source, ok := fieldSources[field.Name]
+			if !ok {
+				source = ValueSourceDefault
+			}
+			v.merge(values, source)
+		}
+	}
+
+	return nil
+}
+
+func (v *SourcedValues) merge(values Values, source string) {
+	if len(values) == 0 {
+		return
+	}
+
+	v.Values = fileutils.MergeMaps(v.Values, values)
+	walkLeaves(values, nil, func(path []string) {
+		v.Sources[joinPath(path)] = source
+	})
+}
+
+func (v *SourcedValues) annotate(node *yaml.Node, path []string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keyPath := append(path[:len(path):len(path)], key.Value)
+
+		if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+			v.annotate(value, keyPath)
+			continue
+		}
+
+		source, ok := v.Sources[joinPath(keyPath)]
+		if !ok {
+			continue
+		}
+
+		// The comment of block collection should be attached to the key, otherwise it will be dropped.
+		if value.Kind != yaml.ScalarNode && len(value.Content) > 0 {
+			key.LineComment = source
+		} else {
+			value.LineComment = source
+		}
+	}
+}
+
+// walkLeaves calls fn with the path of each leaf in values, the empty map is also regarded as a leaf.
+func walkLeaves(values map[string]interface{}, path []string, fn func(path []string)) {
+	for k, v := range values {
+		keyPath := append(path[:len(path):len(path)], k)
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			walkLeaves(m, keyPath, fn)
+			continue
+		}
+		fn(keyPath)
+	}
+}
+
+// joinPath joins the keys with '.', and the '.' in keys will be escaped like '--set'.
+func joinPath(path []string) string {
+	keys := make([]string, 0, len(path))
+	for _, key := range path {
+		keys = append(keys, strings.ReplaceAll(key, ".", `\.`))
+	}
+	return strings.Join(keys, ".")
+}
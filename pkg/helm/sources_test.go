@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helm
+
+import (
+	"testing"
+)
+
+func TestSourcedValues(t *testing.T) {
+	defaults := Values{
+		"image": map[string]interface{}{
+			"registry": "docker.io",
+			"tag":      "latest",
+		},
+		"tolerations": []interface{}{},
+	}
+	inputVals := struct {
+		ImageRegistry string `helm:"image.registry"`
+		StorageSize   string `helm:"storage.size"`
+		ConfigValues  string `helm:"*"`
+	}{
+		ImageRegistry: "greptime-registry.cn-hangzhou.cr.aliyuncs.com",
+		StorageSize:   "10Gi",
+		ConfigValues:  `annotations.foo\.io/bar=baz`,
+	}
+	inline := Values{
+		"replicas": 3,
+	}
+
+	// The StorageSize is not set by users, so it's the default of gtctl.
+	fieldSources := map[string]string{
+		"ImageRegistry": ValueSourceFlag,
+		"ConfigValues":  ValueSourceFlag,
+	}
+
+	v, err := toSourcedValues(defaults, inputVals, fieldSources, []string{"testdata/values-override.yaml"}, inline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := v.OutputAnnotatedValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `annotations:
+  foo.io/bar: baz # flag
+image:
+  registry: greptime-registry.cn-hangzhou.cr.aliyuncs.com # flag
+  tag: v0.4.0 # file
+replicas: 3 # spec
+resources:
+  limits:
+    memory: 256Mi # file
+storage:
+  size: 10Gi # default
+tolerations: [] # default
+`
+	if string(output) != expected {
+		t.Errorf("expected %s, got %s", expected, string(output))
+	}
+}
@@ -24,6 +24,8 @@ import (
 
 	"helm.sh/helm/v3/pkg/strvals"
 	"sigs.k8s.io/yaml"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
 )
 
 const (
@@ -52,16 +54,29 @@ func NewFromFile(filename string) (Values, error) {
 // If there is the same key in both the input struct and the local yaml values file, the value in the input struct will be used.
 // valuesFile can be empty.
 func ToHelmValues(input interface{}, valuesFile string) (Values, error) {
-	var (
-		base Values
-		err  error
-	)
-
+	var valuesFiles []string
 	if len(valuesFile) > 0 {
-		base, err = NewFromFile(valuesFile)
+		valuesFiles = append(valuesFiles, valuesFile)
+	}
+	return toHelmValues(input, valuesFiles, nil)
+}
+
+// toHelmValues is the same as ToHelmValues but with multiple values files and the inline values.
+// Like '-f a.yaml -f b.yaml' of Helm, the values files are merged recursively in order.
+// The precedence from low to high is: valuesFiles, inline and input struct.
+func toHelmValues(input interface{}, valuesFiles []string, inline Values) (Values, error) {
+	var base Values
+
+	for _, valuesFile := range valuesFiles {
+		values, err := NewFromFile(valuesFile)
 		if err != nil {
 			return nil, err
 		}
+		base = fileutils.MergeMaps(base, values)
+	}
+
+	if len(inline) > 0 {
+		base = fileutils.MergeMaps(base, inline)
 	}
 
 	vals, err := struct2Values(input)
@@ -69,7 +84,7 @@ func ToHelmValues(input interface{}, valuesFile string) (Values, error) {
 		return nil, err
 	}
 
-	return mergeMaps(base, vals), nil
+	return fileutils.MergeMaps(base, vals), nil
 }
 
 // OutputValues returns the values as a yaml byte array.
@@ -81,30 +96,12 @@ func (v Values) OutputValues() ([]byte, error) {
 	return data, nil
 }
 
-// mergeMaps merges two maps recursively.
-// The function is copied from 'helm/helm/pkg/cli/values/options.go'.
-func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
-	out := make(map[string]interface{}, len(a))
-	for k, v := range a {
-		out[k] = v
-	}
-	for k, v := range b {
-		if v, ok := v.(map[string]interface{}); ok {
-			if bv, ok := out[k]; ok {
-				if bv, ok := bv.(map[string]interface{}); ok {
-					out[k] = mergeMaps(bv, v)
-					continue
-				}
-			}
-		}
-		out[k] = v
-	}
-	return out
-}
-
 // struct2Values converts the input struct to a helm values map.
+// Besides the `helm:"key"` and `helm:"*"` annotations, the raw values can also be annotated with
+// `helm:"*string"`, `helm:"*file"` and `helm:"*json"`, which are parsed as '--set-string', '--set-file'
+// and '--set-json' of Helm. Like Helm, the precedence from low to high is: json, normal, string and file.
 func struct2Values(input interface{}) (Values, error) {
-	var rawArgs []string
+	var rawArgs, stringArgs, fileArgs, jsonArgs []string
 	valueOf := reflect.ValueOf(input)
 
 	// Make sure we are handling with a struct here.
@@ -118,21 +115,55 @@ func struct2Values(input interface{}) (Values, error) {
 		if len(helmValueKey) > 0 && valueOf.Field(i).Len() > 0 {
 			// If the struct annotation is `helm:"*"`, the value will be added to the rawArgs directly.
 			// Otherwise, the value will be added to the rawArgs with the key `helmValueKey`.
-			if helmValueKey == "*" {
+			switch helmValueKey {
+			case "*":
 				rawArgs = append(rawArgs, valueOf.Field(i).String())
-			} else {
+			case "*string":
+				stringArgs = append(stringArgs, valueOf.Field(i).String())
+			case "*file":
+				fileArgs = append(fileArgs, valueOf.Field(i).String())
+			case "*json":
+				jsonArgs = append(jsonArgs, valueOf.Field(i).String())
+			default:
 				rawArgs = append(rawArgs, fmt.Sprintf("%s=%s", helmValueKey, valueOf.Field(i)))
 			}
 		}
 	}
 
+	if len(rawArgs) == 0 && len(stringArgs) == 0 && len(fileArgs) == 0 && len(jsonArgs) == 0 {
+		return nil, nil
+	}
+
+	values := make(map[string]interface{})
+	for _, arg := range jsonArgs {
+		if err := strvals.ParseJSON(arg, values); err != nil {
+			return nil, fmt.Errorf("failed parsing json values '%s': %v", arg, err)
+		}
+	}
 	if len(rawArgs) > 0 {
-		values := make(map[string]interface{})
 		if err := strvals.ParseInto(strings.Join(rawArgs, ","), values); err != nil {
 			return nil, err
 		}
-		return values, nil
 	}
+	for _, arg := range stringArgs {
+		if err := strvals.ParseIntoString(arg, values); err != nil {
+			return nil, fmt.Errorf("failed parsing string values '%s': %v", arg, err)
+		}
+	}
+	for _, arg := range fileArgs {
+		if err := strvals.ParseIntoFile(arg, values, readValueFile); err != nil {
+			return nil, fmt.Errorf("failed parsing file values '%s': %v", arg, err)
+		}
+	}
+
+	return values, nil
+}
 
-	return nil, nil
+// readValueFile reads the content of file as the value of '--set-file'.
+func readValueFile(path []rune) (interface{}, error) {
+	data, err := os.ReadFile(string(path))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
 }
@@ -18,6 +18,7 @@ package helm
 
 import (
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -72,3 +73,62 @@ func TestToHelmValues(t *testing.T) {
 		t.Errorf("expected %s, got %s", string(original), string(output))
 	}
 }
+
+func TestToHelmValuesWithTypedConfig(t *testing.T) {
+	inputVals := struct {
+		ConfigValues       string `helm:"*"`
+		StringConfigValues string `helm:"*string"`
+		FileConfigValues   string `helm:"*file"`
+		JSONConfigValues   string `helm:"*json"`
+	}{
+		ConfigValues:       `image.tag=v0.4.0,tags={a,b},annotations.foo\.io/bar=baz`,
+		StringConfigValues: "replicas=3",
+		FileConfigValues:   "config=testdata/config.toml",
+		JSONConfigValues:   `resources={"limits":{"cpu":"1"}},image.tag="latest"`,
+	}
+
+	v, err := ToHelmValues(inputVals, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Values{
+		"image":       map[string]interface{}{"tag": "v0.4.0"},
+		"tags":        []interface{}{"a", "b"},
+		"annotations": map[string]interface{}{"foo.io/bar": "baz"},
+		"replicas":    "3",
+		"config":      "[logging]\nlevel = \"info\"\n",
+		"resources":   map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+	}
+	if !reflect.DeepEqual(expected, v) {
+		t.Errorf("expected %v, got %v", expected, v)
+	}
+}
+
+func TestToHelmValuesWithMultipleValuesFiles(t *testing.T) {
+	inputVals := struct {
+		ConfigValues string `helm:"*"`
+	}{
+		ConfigValues: "replicas=3",
+	}
+
+	v, err := toHelmValues(inputVals, []string{"testdata/values.yaml", "testdata/values-override.yaml"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := v["image"].(map[string]interface{})
+	if image["tag"] != "v0.4.0" || image["repository"] != "greptime/greptimedb-operator" {
+		t.Errorf("unexpected image values: %v", image)
+	}
+
+	limits := v["resources"].(map[string]interface{})["limits"].(map[string]interface{})
+	if limits["memory"] != "256Mi" || limits["cpu"] != "500m" {
+		t.Errorf("unexpected resources limits: %v", limits)
+	}
+
+	// The values set in command line are applied last.
+	if v["replicas"] != int64(3) {
+		t.Errorf("expected replicas 3, got %v", v["replicas"])
+	}
+}
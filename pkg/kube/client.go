@@ -63,7 +63,9 @@ var greptimeDBClusterGVR = schema.GroupVersionResource{
 	Resource: "greptimedbclusters",
 }
 
-func NewClient(kubeconfig string) (*Client, error) {
+// NewClient creates the client with the kubeconfig and the context in it.
+// If kubeconfig is empty, it will use '~/.kube/config'. If kubeContext is empty, it will use the current context in kubeconfig.
+func NewClient(kubeconfig, kubeContext string) (*Client, error) {
 	if kubeconfig == "" {
 		if home := homedir.HomeDir(); home != "" {
 			kubeconfig = filepath.Join(home, ".kube", "config")
@@ -72,10 +74,11 @@ func NewClient(kubeconfig string) (*Client, error) {
 		}
 	}
 
-	// use the current context in kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext}).ClientConfig()
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 
 	kubeClient, err := kubernetes.NewForConfig(config)
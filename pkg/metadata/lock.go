@@ -0,0 +1,179 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// ClusterLocksDir is the directory of the cluster lock files, it's in the working directory.
+// The lock files are not in the cluster scope directories because they outlive the deletion of cluster.
+const ClusterLocksDir = "locks"
+
+// ClusterLockOwner is the process that holds the lock of a cluster.
+type ClusterLockOwner struct {
+	Pid       int       `yaml:"pid"`
+	Operation string    `yaml:"operation,omitempty"`
+	Since     time.Time `yaml:"since"`
+}
+
+// ClusterLock is the exclusive lock of a cluster that is held by current process.
+// The lock is released when the process exits, even if it's killed without unlocking.
+type ClusterLock struct {
+	// Reclaimed is the owner of the stale lock that was left by a dead process, it's nil if the lock was free.
+	Reclaimed *ClusterLockOwner
+
+	file *os.File
+}
+
+func (m *manager) LockCluster(mode ClusterMode, namespace, name, operation string) (*ClusterLock, error) {
+	dir := filepath.Join(m.workingDir, ClusterLocksDir)
+	if err := fileutils.EnsureDir(dir); err != nil {
+		return nil, err
+	}
+
+	lockFile := filepath.Join(dir, clusterLockFileName(mode, namespace, name))
+	for {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = fileutils.TryLockFile(f); err != nil {
+			owner := readClusterLockOwner(f)
+			f.Close()
+			if err == fileutils.ErrLocked {
+				return nil, clusterLockedError(namespace, name, owner)
+			}
+			return nil, fmt.Errorf("failed to lock '%s': %v", lockFile, err)
+		}
+
+		// The previous owner removes the lock file before unlocking, the lock on the removed file is useless.
+		if !isSameFile(f, lockFile) {
+			f.Close()
+			continue
+		}
+
+		lock := &ClusterLock{file: f}
+
+		// The lock file is removed when unlocking, so the owner in it is dead if it's still there.
+		lock.Reclaimed = readClusterLockOwner(f)
+
+		if err = writeClusterLockOwner(f, &ClusterLockOwner{
+			Pid:       os.Getpid(),
+			Operation: operation,
+			Since:     time.Now(),
+		}); err != nil {
+			lock.Unlock()
+			return nil, fmt.Errorf("failed to write '%s': %v", lockFile, err)
+		}
+
+		return lock, nil
+	}
+}
+
+// Unlock releases the lock. It's safe to unlock more than once.
+func (l *ClusterLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+
+	// The lock file is removed without removing the lock file of the next owner.
+	err := fileutils.RemoveLockFile(l.file)
+	l.file = nil
+
+	return err
+}
+
+// clusterLockFileName returns the name of the lock file, e.g. 'bare-metal.mycluster.lock' or 'kubernetes.default.mycluster.lock'.
+func clusterLockFileName(mode ClusterMode, namespace, name string) string {
+	parts := []string{string(mode)}
+	if len(namespace) > 0 {
+		parts = append(parts, namespace)
+	}
+	return strings.Join(append(parts, name, "lock"), ".")
+}
+
+func clusterLockedError(namespace, name string, owner *ClusterLockOwner) error {
+	cluster := fmt.Sprintf("cluster '%s'", name)
+	if len(namespace) > 0 {
+		cluster = fmt.Sprintf("cluster '%s' in namespace '%s'", name, namespace)
+	}
+
+	// The owner may have not written itself into the lock file yet.
+	if owner == nil {
+		return fmt.Errorf("%s is locked by another gtctl process, please retry after it finishes", cluster)
+	}
+	return fmt.Errorf("%s is locked by process %d ('%s') since %s, please retry after it finishes",
+		cluster, owner.Pid, owner.Operation, owner.Since.Format(time.RFC3339))
+}
+
+// readClusterLockOwner reads the owner in the lock file, it returns nil if there is no valid owner.
+func readClusterLockOwner(f *os.File) *ClusterLockOwner {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+	in, err := io.ReadAll(f)
+	if err != nil || len(in) == 0 {
+		return nil
+	}
+
+	var owner ClusterLockOwner
+	if err = yaml.Unmarshal(in, &owner); err != nil || owner.Pid == 0 {
+		return nil
+	}
+
+	return &owner
+}
+
+func writeClusterLockOwner(f *os.File, owner *ClusterLockOwner) error {
+	out, err := yaml.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	if err = f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err = f.WriteAt(out, 0); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// isSameFile checks whether the opened file is still the file in path.
+func isSameFile(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(opened, current)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockCluster(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	m, err := New(tempDir)
+	assert.NoError(t, err)
+
+	lock, err := m.LockCluster(ClusterModeBareMetal, "", "mycluster", "gtctl cluster create")
+	assert.NoError(t, err)
+	assert.Nil(t, lock.Reclaimed)
+
+	// The lock is exclusive and the error tells who holds it.
+	_, err = m.LockCluster(ClusterModeBareMetal, "", "mycluster", "gtctl cluster delete")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("locked by process %d ('gtctl cluster create')", os.Getpid()))
+
+	// The cluster with the same name in Kubernetes is another cluster.
+	other, err := m.LockCluster(ClusterModeKubernetes, "default", "mycluster", "gtctl cluster scale")
+	assert.NoError(t, err)
+	assert.NoError(t, other.Unlock())
+
+	// The lock can be acquired again after it's released.
+	assert.NoError(t, lock.Unlock())
+	assert.NoError(t, lock.Unlock())
+	lock, err = m.LockCluster(ClusterModeBareMetal, "", "mycluster", "gtctl cluster delete")
+	assert.NoError(t, err)
+	assert.Nil(t, lock.Reclaimed)
+	assert.NoError(t, lock.Unlock())
+
+	lockFile := filepath.Join(tempDir, BaseDir, ClusterLocksDir, "bare-metal.mycluster.lock")
+	_, err = os.Stat(lockFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReclaimStaleClusterLock(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	m, err := New(tempDir)
+	assert.NoError(t, err)
+
+	// The lock file left by a dead process that was not unlocked.
+	lockDir := filepath.Join(tempDir, BaseDir, ClusterLocksDir)
+	assert.NoError(t, os.MkdirAll(lockDir, 0755))
+	stale := "pid: 4194304\noperation: gtctl cluster create\nsince: 2023-10-01T00:00:00Z\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(lockDir, "kubernetes.default.mycluster.lock"), []byte(stale), 0644))
+
+	lock, err := m.LockCluster(ClusterModeKubernetes, "default", "mycluster", "gtctl cluster scale")
+	assert.NoError(t, err)
+	assert.NotNil(t, lock.Reclaimed)
+	assert.Equal(t, 4194304, lock.Reclaimed.Pid)
+	assert.Equal(t, "gtctl cluster create", lock.Reclaimed.Operation)
+
+	// The lock file is owned by current process now.
+	_, err = m.LockCluster(ClusterModeKubernetes, "default", "mycluster", "gtctl cluster delete")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("process %d ('gtctl cluster scale')", os.Getpid()))
+	assert.NoError(t, lock.Unlock())
+}
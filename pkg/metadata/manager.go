@@ -28,6 +28,7 @@ import (
 	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
 	"github.com/GreptimeTeam/gtctl/pkg/config"
 	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+	"github.com/GreptimeTeam/gtctl/pkg/utils/home"
 )
 
 // Manager is the interface of the metadata manager.
@@ -49,9 +50,39 @@ type Manager interface {
 	// CreateClusterScopeDirs creates cluster scope directories and config path that allocated by AllocateClusterScopeDirs.
 	CreateClusterScopeDirs(cfg *config.BareMetalClusterConfig) error
 
+	// UpdateClusterConfig updates the config in the metadata of current cluster, the other fields are kept.
+	UpdateClusterConfig(cfg *config.BareMetalClusterConfig) error
+
 	// GetClusterScopeDirs returns the cluster scope directory of current cluster.
 	GetClusterScopeDirs() *ClusterScopeDirs
 
+	// RegisterCluster records the cluster in the cluster registry, the record of the same cluster is replaced
+	// if its state can be registered again in the state of the new record.
+	RegisterCluster(record *ClusterRecord) error
+
+	// SetClusterState transits the registered cluster to the state for the reason.
+	// It returns error if the cluster is not registered or the transition is not allowed.
+	SetClusterState(mode ClusterMode, namespace, name string, state ClusterState, reason string) error
+
+	// LockCluster acquires the exclusive lock of the cluster for the operation, such as creating, deleting or scaling.
+	// It fails fast if the lock is held by another process, and the stale lock of a dead process is reclaimed.
+	LockCluster(mode ClusterMode, namespace, name, operation string) (*ClusterLock, error)
+
+	// UnregisterCluster removes the cluster from the cluster registry.
+	UnregisterCluster(mode ClusterMode, namespace, name string) error
+
+	// GetClusterRecord returns the record of the cluster, or nil if the cluster is not registered.
+	GetClusterRecord(mode ClusterMode, namespace, name string) (*ClusterRecord, error)
+
+	// ListClusterRecords returns the records of all the registered clusters in the order of creation.
+	ListClusterRecords() ([]*ClusterRecord, error)
+
+	// AppendHistory appends the entry to the operation history, the secrets in its options are masked.
+	AppendHistory(entry *HistoryEntry) error
+
+	// ListHistory returns the operation history in the order of time, only the entries of the cluster are returned if it's not empty.
+	ListHistory(cluster string) ([]*HistoryEntry, error)
+
 	// Clean cleans up all the metadata. It will remove the working directory.
 	Clean() error
 }
@@ -59,7 +90,11 @@ type Manager interface {
 const (
 	// BaseDir is the working directory of gtctl and
 	// all the metadata will be stored in ${HomeDir}/${BaseDir}.
-	BaseDir = ".gtctl"
+	BaseDir = home.BaseDir
+
+	// HomeDirEnv is the environment variable of the home directory of gtctl,
+	// it's used when the home directory is not specified and defaults to the home directory of current user.
+	HomeDirEnv = home.DirEnv
 
 	ClusterLogsDir = "logs"
 	ClusterDataDir = "data"
@@ -82,18 +117,15 @@ type manager struct {
 
 var _ Manager = &manager{}
 
+// New creates the metadata manager that works in ${homeDir}/${BaseDir}.
+// If homeDir is empty, it uses 'GTCTL_HOME' or the home directory of current user.
 func New(homeDir string) (Manager, error) {
-	m := &manager{}
-	if homeDir == "" {
-		dir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
-		}
-		m.workingDir = filepath.Join(dir, BaseDir)
-	} else {
-		m.workingDir = filepath.Join(homeDir, BaseDir)
+	workingDir, err := home.WorkingDir(homeDir)
+	if err != nil {
+		return nil, err
 	}
-	return m, nil
+
+	return &manager{workingDir: workingDir}, nil
 }
 
 func (m *manager) AllocateClusterScopeDirs(clusterName string) {
@@ -151,11 +183,6 @@ func (m *manager) CreateClusterScopeDirs(cfg *config.BareMetalClusterConfig) err
 		}
 	}
 
-	f, err := os.Create(m.clusterDir.ConfigPath)
-	if err != nil {
-		return err
-	}
-
 	metaConfig := config.BareMetalClusterMetadata{
 		Config:        cfg,
 		CreationDate:  time.Now(),
@@ -168,15 +195,32 @@ func (m *manager) CreateClusterScopeDirs(cfg *config.BareMetalClusterConfig) err
 		return err
 	}
 
-	if _, err = f.Write(out); err != nil {
+	// The config is replaced atomically, so that it's never partially written.
+	return fileutils.WriteFileAtomic(m.clusterDir.ConfigPath, out, 0644)
+}
+
+func (m *manager) UpdateClusterConfig(cfg *config.BareMetalClusterConfig) error {
+	if m.clusterDir == nil {
+		return fmt.Errorf("unallocated cluster dir, please initialize a metadata manager with cluster name provided")
+	}
+
+	in, err := os.ReadFile(m.clusterDir.ConfigPath)
+	if err != nil {
 		return err
 	}
 
-	if err = f.Close(); err != nil {
+	var metaConfig config.BareMetalClusterMetadata
+	if err = yaml.Unmarshal(in, &metaConfig); err != nil {
 		return err
 	}
+	metaConfig.Config = cfg
 
-	return nil
+	out, err := yaml.Marshal(metaConfig)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(m.clusterDir.ConfigPath, out, 0644)
 }
 
 func (m *manager) SetHomeDir(dir string) error {
@@ -105,6 +105,8 @@ func TestMetadataManager(t *testing.T) {
 }
 
 func TestCreateMetadataManagerWithEmptyHomeDir(t *testing.T) {
+	t.Setenv(HomeDirEnv, "")
+
 	m, err := New("")
 	if err != nil {
 		t.Fatalf("failed to create metadata manager: %v", err)
@@ -121,6 +123,27 @@ func TestCreateMetadataManagerWithEmptyHomeDir(t *testing.T) {
 	}
 }
 
+func TestCreateMetadataManagerWithHomeDirEnv(t *testing.T) {
+	t.Setenv(HomeDirEnv, "/tmp/gtctl-home")
+
+	m, err := New("")
+	if err != nil {
+		t.Fatalf("failed to create metadata manager: %v", err)
+	}
+	if m.GetWorkingDir() != filepath.Join("/tmp/gtctl-home", BaseDir) {
+		t.Errorf("got %s, wanted %s", m.GetWorkingDir(), filepath.Join("/tmp/gtctl-home", BaseDir))
+	}
+
+	// The specified home directory takes precedence over the environment variable.
+	m, err = New("/tmp")
+	if err != nil {
+		t.Fatalf("failed to create metadata manager: %v", err)
+	}
+	if m.GetWorkingDir() != filepath.Join("/tmp", BaseDir) {
+		t.Errorf("got %s, wanted %s", m.GetWorkingDir(), filepath.Join("/tmp", BaseDir))
+	}
+}
+
 func TestMetadataManagerWithClusterConfigPath(t *testing.T) {
 	m, err := New("/tmp")
 	assert.NoError(t, err)
@@ -142,6 +165,20 @@ func TestMetadataManagerWithClusterConfigPath(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expect, actual.Config)
 
+	// Update the config with the resolved version.
+	expect.Cluster.Artifact.Version = "v0.4.0"
+	err = m.UpdateClusterConfig(expect)
+	assert.NoError(t, err)
+
+	cnt, err = os.ReadFile(csd.ConfigPath)
+	assert.NoError(t, err)
+
+	var updated config.BareMetalClusterMetadata
+	err = yaml.Unmarshal(cnt, &updated)
+	assert.NoError(t, err)
+	assert.Equal(t, expect, updated.Config)
+	assert.Equal(t, actual.CreationDate, updated.CreationDate)
+
 	err = m.Clean()
 	assert.NoError(t, err)
 }
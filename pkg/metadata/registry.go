@@ -0,0 +1,204 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+const (
+	// ClusterRegistryFile is the file that records all the clusters created by gtctl, it's in the working directory.
+	ClusterRegistryFile = "clusters.yaml"
+
+	clusterRegistryLockFile = ".clusters.lock"
+)
+
+// ClusterMode is the mode that the cluster is deployed in.
+type ClusterMode string
+
+const (
+	ClusterModeKubernetes ClusterMode = "kubernetes"
+	ClusterModeBareMetal  ClusterMode = "bare-metal"
+)
+
+// ClusterRecord is the record of one cluster that gtctl created.
+// The cluster is identified by its mode, namespace and name, and the namespace of the cluster on bare-metal is empty.
+type ClusterRecord struct {
+	Name      string      `yaml:"name"`
+	Mode      ClusterMode `yaml:"mode"`
+	Namespace string      `yaml:"namespace,omitempty"`
+
+	// Kubeconfig and KubeContext are where the cluster in Kubernetes is, the empty ones mean the defaults.
+	Kubeconfig  string `yaml:"kubeconfig,omitempty"`
+	KubeContext string `yaml:"kubeContext,omitempty"`
+
+	// Versions are the versions of charts or binaries that the cluster uses, keyed by the artifact name.
+	Versions map[string]string `yaml:"versions,omitempty"`
+
+	// Options are the options that the cluster is created or updated with, e.g. the command line flags.
+	Options map[string]string `yaml:"options,omitempty"`
+
+	// State is the lifecycle state of the cluster, and Reason is why the cluster is in the state.
+	State              ClusterState `yaml:"state"`
+	Reason             string       `yaml:"reason,omitempty"`
+	LastTransitionTime time.Time    `yaml:"lastTransitionTime"`
+
+	CreationDate time.Time `yaml:"creationDate"`
+	UpdateDate   time.Time `yaml:"updateDate"`
+}
+
+type clusterRegistry struct {
+	Clusters []*ClusterRecord `yaml:"clusters"`
+}
+
+func (r *ClusterRecord) is(mode ClusterMode, namespace, name string) bool {
+	return r.Mode == mode && r.Namespace == namespace && r.Name == name
+}
+
+func (m *manager) RegisterCluster(record *ClusterRecord) error {
+	if len(record.Name) == 0 || len(record.Mode) == 0 {
+		return fmt.Errorf("the name and mode of cluster should be set")
+	}
+
+	return m.updateClusterRegistry(func(registry *clusterRegistry) error {
+		now := time.Now()
+		record.UpdateDate = now
+		for i, r := range registry.Clusters {
+			if r.is(record.Mode, record.Namespace, record.Name) {
+				if !r.State.CanRegister(record.State) {
+					return fmt.Errorf("cluster '%s' can't be registered again from '%s' to '%s'", record.Name, r.State, record.State)
+				}
+
+				// The creation date is kept when the cluster is updated, so is the transition time if the state isn't changed.
+				record.CreationDate = r.CreationDate
+				record.LastTransitionTime = r.LastTransitionTime
+				if record.State != r.State {
+					record.LastTransitionTime = now
+				}
+				registry.Clusters[i] = record
+				return nil
+			}
+		}
+
+		record.CreationDate = now
+		record.LastTransitionTime = now
+		registry.Clusters = append(registry.Clusters, record)
+		return nil
+	})
+}
+
+func (m *manager) UnregisterCluster(mode ClusterMode, namespace, name string) error {
+	return m.updateClusterRegistry(func(registry *clusterRegistry) error {
+		for i, r := range registry.Clusters {
+			if r.is(mode, namespace, name) {
+				registry.Clusters = append(registry.Clusters[:i], registry.Clusters[i+1:]...)
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+func (m *manager) GetClusterRecord(mode ClusterMode, namespace, name string) (*ClusterRecord, error) {
+	registry, err := m.readClusterRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range registry.Clusters {
+		if r.is(mode, namespace, name) {
+			return r, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *manager) ListClusterRecords() ([]*ClusterRecord, error) {
+	registry, err := m.readClusterRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(registry.Clusters, func(i, j int) bool {
+		return registry.Clusters[i].CreationDate.Before(registry.Clusters[j].CreationDate)
+	})
+
+	return registry.Clusters, nil
+}
+
+// readClusterRegistry reads the registry file, the registry is empty if the file doesn't exist.
+func (m *manager) readClusterRegistry() (*clusterRegistry, error) {
+	registry := &clusterRegistry{}
+
+	in, err := os.ReadFile(filepath.Join(m.workingDir, ClusterRegistryFile))
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(in, registry); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster registry: %v", err)
+	}
+
+	return registry, nil
+}
+
+// updateClusterRegistry updates the registry by update under the lock, so that concurrent gtctl processes
+// won't lose the records of each other. The registry file is replaced atomically.
+func (m *manager) updateClusterRegistry(update func(*clusterRegistry) error) error {
+	if err := fileutils.EnsureDir(m.workingDir); err != nil {
+		return err
+	}
+
+	lockFile := filepath.Join(m.workingDir, clusterRegistryLockFile)
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = fileutils.LockFile(f); err != nil {
+		return fmt.Errorf("failed to lock '%s': %v", lockFile, err)
+	}
+	defer fileutils.UnlockFile(f)
+
+	registry, err := m.readClusterRegistry()
+	if err != nil {
+		return err
+	}
+	if err = update(registry); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(registry)
+	if err != nil {
+		return err
+	}
+
+	return fileutils.WriteFileAtomic(filepath.Join(m.workingDir, ClusterRegistryFile), out, 0644)
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterRegistry(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	m, err := New(tempDir)
+	assert.NoError(t, err)
+
+	// The registry is empty before any cluster is registered.
+	records, err := m.ListClusterRecords()
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	err = m.RegisterCluster(&ClusterRecord{
+		Name:        "mycluster",
+		Mode:        ClusterModeKubernetes,
+		Namespace:   "default",
+		KubeContext: "kind-gtctl",
+		Versions:    map[string]string{"greptimedb-cluster": "0.1.2"},
+		State:       ClusterStateCreating,
+	})
+	assert.NoError(t, err)
+
+	// The cluster with the same name on bare-metal is another cluster.
+	err = m.RegisterCluster(&ClusterRecord{
+		Name:     "mycluster",
+		Mode:     ClusterModeBareMetal,
+		Versions: map[string]string{"greptime": "v0.4.1"},
+		State:    ClusterStateRunning,
+	})
+	assert.NoError(t, err)
+
+	record, err := m.GetClusterRecord(ClusterModeKubernetes, "default", "mycluster")
+	assert.NoError(t, err)
+	assert.NotNil(t, record)
+	assert.Equal(t, "kind-gtctl", record.KubeContext)
+	assert.Equal(t, ClusterStateCreating, record.State)
+	creationDate := record.CreationDate
+
+	// Update the cluster, the creation date is kept.
+	err = m.RegisterCluster(&ClusterRecord{
+		Name:      "mycluster",
+		Mode:      ClusterModeKubernetes,
+		Namespace: "default",
+		Versions:  map[string]string{"greptimedb-cluster": "0.1.3"},
+		State:     ClusterStateRunning,
+	})
+	assert.NoError(t, err)
+	record, err = m.GetClusterRecord(ClusterModeKubernetes, "default", "mycluster")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1.3", record.Versions["greptimedb-cluster"])
+	assert.True(t, creationDate.Equal(record.CreationDate))
+
+	assert.NoError(t, m.SetClusterState(ClusterModeKubernetes, "default", "mycluster", ClusterStateFailed, "failed to apply"))
+	record, err = m.GetClusterRecord(ClusterModeKubernetes, "default", "mycluster")
+	assert.NoError(t, err)
+	assert.Equal(t, ClusterStateFailed, record.State)
+	assert.Error(t, m.SetClusterState(ClusterModeKubernetes, "other", "mycluster", ClusterStateFailed, ""))
+
+	// The cluster that is being deleted can't be registered again.
+	assert.NoError(t, m.SetClusterState(ClusterModeKubernetes, "default", "mycluster", ClusterStateDeleting, ""))
+	err = m.RegisterCluster(&ClusterRecord{
+		Name:      "mycluster",
+		Mode:      ClusterModeKubernetes,
+		Namespace: "default",
+		State:     ClusterStateRunning,
+	})
+	assert.Error(t, err)
+	record, err = m.GetClusterRecord(ClusterModeKubernetes, "default", "mycluster")
+	assert.NoError(t, err)
+	assert.Equal(t, ClusterStateDeleting, record.State)
+
+	records, err = m.ListClusterRecords()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	assert.NoError(t, m.UnregisterCluster(ClusterModeKubernetes, "default", "mycluster"))
+	record, err = m.GetClusterRecord(ClusterModeKubernetes, "default", "mycluster")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	record, err = m.GetClusterRecord(ClusterModeBareMetal, "", "mycluster")
+	assert.NoError(t, err)
+	assert.NotNil(t, record)
+}
+
+func TestConcurrentRegisterClusters(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	var wg sync.WaitGroup
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			// Each manager is like a separate gtctl process.
+			m, err := New(tempDir)
+			assert.NoError(t, err)
+			assert.NoError(t, m.RegisterCluster(&ClusterRecord{Name: name, Mode: ClusterModeBareMetal, State: ClusterStateRunning}))
+		}(name)
+	}
+	wg.Wait()
+
+	m, err := New(tempDir)
+	assert.NoError(t, err)
+	records, err := m.ListClusterRecords()
+	assert.NoError(t, err)
+	assert.Len(t, records, len(names))
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClusterState is the lifecycle state of the cluster.
+type ClusterState string
+
+const (
+	// ClusterStateCreating means the cluster is being created, or created again with the same name.
+	ClusterStateCreating ClusterState = "Creating"
+
+	// ClusterStateRunning means all the components of the cluster are running.
+	ClusterStateRunning ClusterState = "Running"
+
+	// ClusterStateDegraded means some components of the cluster are not running, e.g. crashed or failed to scale.
+	ClusterStateDegraded ClusterState = "Degraded"
+
+	// ClusterStateStopping means the cluster is being stopped.
+	ClusterStateStopping ClusterState = "Stopping"
+
+	// ClusterStateStopped means the cluster is stopped, and its data are kept.
+	ClusterStateStopped ClusterState = "Stopped"
+
+	// ClusterStateFailed means the cluster failed to be created, updated or deleted, or stopped because of a crash.
+	ClusterStateFailed ClusterState = "Failed"
+
+	// ClusterStateDeleting means the cluster is being deleted.
+	ClusterStateDeleting ClusterState = "Deleting"
+)
+
+// clusterStateTransitions are the allowed transitions from one state to the others.
+// The transition to the same state is always allowed, which only updates the reason.
+// The cluster that failed is registered as Creating directly when it is created again.
+var clusterStateTransitions = map[ClusterState][]ClusterState{
+	ClusterStateCreating: {ClusterStateRunning, ClusterStateDegraded, ClusterStateStopping, ClusterStateFailed, ClusterStateDeleting},
+	ClusterStateRunning:  {ClusterStateDegraded, ClusterStateStopping, ClusterStateFailed, ClusterStateDeleting},
+	ClusterStateDegraded: {ClusterStateRunning, ClusterStateStopping, ClusterStateFailed, ClusterStateDeleting},
+	ClusterStateStopping: {ClusterStateStopped, ClusterStateFailed},
+	ClusterStateStopped:  {ClusterStateCreating, ClusterStateRunning, ClusterStateDeleting},
+	ClusterStateFailed:   {ClusterStateCreating, ClusterStateRunning, ClusterStateStopping, ClusterStateStopped, ClusterStateDeleting},
+
+	// The cluster that failed to be deleted is back to its previous state.
+	ClusterStateDeleting: {ClusterStateRunning, ClusterStateDegraded, ClusterStateStopped, ClusterStateFailed},
+}
+
+// CanTransit returns true if the cluster in the state can transit to the target state.
+func (s ClusterState) CanTransit(target ClusterState) bool {
+	if s == target {
+		return true
+	}
+	for _, state := range clusterStateTransitions[s] {
+		if state == target {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRegister returns true if the registered cluster in the state can be registered again in the target state.
+// Unlike CanTransit, the cluster that is being deleted can't be registered again, since it may be deleted partially.
+func (s ClusterState) CanRegister(target ClusterState) bool {
+	return s != ClusterStateDeleting && s.CanTransit(target)
+}
+
+func (m *manager) SetClusterState(mode ClusterMode, namespace, name string, state ClusterState, reason string) error {
+	return m.updateClusterRegistry(func(registry *clusterRegistry) error {
+		for _, r := range registry.Clusters {
+			if !r.is(mode, namespace, name) {
+				continue
+			}
+
+			if !r.State.CanTransit(state) {
+				return fmt.Errorf("cluster '%s' can't transit from '%s' to '%s'", name, r.State, state)
+			}
+
+			now := time.Now()
+			if r.State != state {
+				r.LastTransitionTime = now
+			}
+			r.State = state
+			r.Reason = reason
+			r.UpdateDate = now
+
+			return nil
+		}
+		return fmt.Errorf("cluster '%s' is not registered", name)
+	})
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterStateTransitions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	m, err := New(tempDir)
+	assert.NoError(t, err)
+
+	err = m.RegisterCluster(&ClusterRecord{Name: "mycluster", Mode: ClusterModeBareMetal, State: ClusterStateCreating})
+	assert.NoError(t, err)
+
+	// The lifecycle of the cluster on bare-metal whose component crashes.
+	transitions := []struct {
+		state  ClusterState
+		reason string
+		ok     bool
+	}{
+		{ClusterStateRunning, "", true},
+		{ClusterStateStopped, "", false},
+		{ClusterStateDegraded, "component 'datanode.0' exited unexpectedly: exit status 101", true},
+		{ClusterStateFailed, "component 'datanode.0' exited unexpectedly: exit status 101", true},
+		{ClusterStateDeleting, "", true},
+		{ClusterStateCreating, "", false},
+		{ClusterStateFailed, "Failed to delete: permission denied", true},
+	}
+	for _, tt := range transitions {
+		before, err := m.GetClusterRecord(ClusterModeBareMetal, "", "mycluster")
+		assert.NoError(t, err)
+
+		err = m.SetClusterState(ClusterModeBareMetal, "", "mycluster", tt.state, tt.reason)
+		if !tt.ok {
+			assert.Error(t, err, "transit from %s to %s", before.State, tt.state)
+			continue
+		}
+		assert.NoError(t, err, "transit from %s to %s", before.State, tt.state)
+
+		after, err := m.GetClusterRecord(ClusterModeBareMetal, "", "mycluster")
+		assert.NoError(t, err)
+		assert.Equal(t, tt.state, after.State)
+		assert.Equal(t, tt.reason, after.Reason)
+		if before.State == tt.state {
+			// Only the reason is updated.
+			assert.True(t, before.LastTransitionTime.Equal(after.LastTransitionTime))
+		} else {
+			assert.False(t, after.LastTransitionTime.Before(before.LastTransitionTime))
+		}
+	}
+}
+
+func TestClusterStateCanTransit(t *testing.T) {
+	assert.True(t, ClusterStateRunning.CanTransit(ClusterStateRunning))
+	assert.True(t, ClusterStateRunning.CanTransit(ClusterStateStopping))
+	assert.True(t, ClusterStateStopping.CanTransit(ClusterStateStopped))
+	assert.False(t, ClusterStateStopping.CanTransit(ClusterStateRunning))
+	assert.False(t, ClusterStateDeleting.CanTransit(ClusterStateCreating))
+
+	// Every state except Stopping can be deleted.
+	for state := range clusterStateTransitions {
+		assert.Equal(t, state != ClusterStateStopping, state.CanTransit(ClusterStateDeleting), "state %s", state)
+	}
+}
+
+func TestClusterStateCanRegister(t *testing.T) {
+	assert.True(t, ClusterStateFailed.CanRegister(ClusterStateCreating))
+	assert.True(t, ClusterStateStopped.CanRegister(ClusterStateCreating))
+	assert.True(t, ClusterStateDegraded.CanRegister(ClusterStateRunning))
+	assert.False(t, ClusterStateStopping.CanRegister(ClusterStateRunning))
+
+	// The cluster that is being deleted can only be back to its previous state by SetClusterState.
+	assert.True(t, ClusterStateDeleting.CanTransit(ClusterStateRunning))
+	assert.False(t, ClusterStateDeleting.CanRegister(ClusterStateRunning))
+}
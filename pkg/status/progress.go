@@ -0,0 +1,131 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package status
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// progressUpdateInterval is the minimum interval to refresh the progress in spinner.
+	progressUpdateInterval = 200 * time.Millisecond
+)
+
+// Progress reports the progress of transferring data in the spinner, including bytes, rate and ETA.
+// It implements io.Writer so that it can be used with io.TeeReader or io.MultiWriter.
+type Progress struct {
+	spinner *Spinner
+	title   string
+
+	// origin is the status of spinner before the progress starts, it will be restored when the progress is done.
+	origin string
+
+	mu         sync.Mutex
+	total      int64
+	current    int64
+	offset     int64
+	start      time.Time
+	lastUpdate time.Time
+}
+
+// NewProgress creates a progress with the title. The spinner can be nil and then nothing will be reported.
+func NewProgress(spinner *Spinner, title string) *Progress {
+	p := &Progress{
+		spinner: spinner,
+		title:   title,
+		start:   time.Now(),
+	}
+	if spinner != nil {
+		p.origin = spinner.Status()
+	}
+	return p
+}
+
+// Reset resets the progress with the bytes already transferred and the total bytes.
+// The total can be -1 if it's unknown.
+func (p *Progress) Reset(current, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current, p.offset, p.total = current, current, total
+	p.start = time.Now()
+	p.lastUpdate = time.Time{}
+}
+
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current += int64(len(b))
+	if p.spinner != nil && time.Since(p.lastUpdate) >= progressUpdateInterval {
+		p.lastUpdate = time.Now()
+		p.spinner.Update(p.string())
+	}
+
+	return len(b), nil
+}
+
+// Done restores the status of spinner before the progress starts.
+func (p *Progress) Done() {
+	if p.spinner != nil {
+		p.spinner.Update(p.origin)
+	}
+}
+
+// String returns the progress like 'Downloading foo: 12.0 MiB / 100.0 MiB, 5.1 MiB/s, ETA 17s'.
+func (p *Progress) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.string()
+}
+
+func (p *Progress) string() string {
+	var rate float64
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		rate = float64(p.current-p.offset) / elapsed
+	}
+
+	if p.total <= 0 {
+		return fmt.Sprintf("%s: %s, %s/s", p.title, formatBytes(float64(p.current)), formatBytes(rate))
+	}
+
+	eta := "unknown"
+	if rate > 0 {
+		eta = time.Duration(float64(p.total-p.current) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("%s: %s / %s, %s/s, ETA %s", p.title,
+		formatBytes(float64(p.current)), formatBytes(float64(p.total)), formatBytes(rate), eta)
+}
+
+// formatBytes formats the bytes in binary units, for example, '1.5 MiB'.
+func formatBytes(n float64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%.0f B", n)
+	}
+
+	units := []string{"KiB", "MiB", "GiB", "TiB"}
+	i := -1
+	for n >= unit && i < len(units)-1 {
+		n /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", n, units[i])
+}
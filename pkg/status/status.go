@@ -18,6 +18,7 @@ package status
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -65,6 +66,20 @@ func (s *Spinner) Start(status string) {
 	s.spinner.Suffix = fmt.Sprintf(" %s", status)
 }
 
+// Update updates the status of the spinner without restarting it.
+func (s *Spinner) Update(status string) {
+	s.spinner.Lock()
+	s.spinner.Suffix = fmt.Sprintf(" %s", status)
+	s.spinner.Unlock()
+}
+
+// Status returns the current status of the spinner.
+func (s *Spinner) Status() string {
+	s.spinner.Lock()
+	defer s.spinner.Unlock()
+	return strings.TrimPrefix(s.spinner.Suffix, " ")
+}
+
 func (s *Spinner) Stop(success bool, status string) {
 	if success {
 		s.spinner.FinalMSG = fmt.Sprintf(" \x1b[32m✓\x1b[0m %s\n", status)
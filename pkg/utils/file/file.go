@@ -19,13 +19,15 @@ package file
 import (
 	"archive/tar"
 	"archive/zip"
-	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
-	"path"
 	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
 )
 
 // EnsureDir ensures the directory exists.
@@ -89,25 +91,119 @@ func CopyFile(src, dst string) error {
 	return w.Sync()
 }
 
+// WriteFileAtomic writes data to the file by renaming a temporary file in the same directory,
+// so the readers see either the old file or the new one, never the partially written one.
+func WriteFileAtomic(file string, data []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err = tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err = tempFile.Chmod(perm); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), file)
+}
+
 const (
-	ZipExtension   = ".zip"
-	TarGzExtension = ".tar.gz"
-	TgzExtension   = ".tgz"
-	GzExtension    = ".gz"
-	TarExtension   = ".tar"
+	ZipExtension    = ".zip"
+	TarGzExtension  = ".tar.gz"
+	TgzExtension    = ".tgz"
+	GzExtension     = ".gz"
+	TarExtension    = ".tar"
+	TarXzExtension  = ".tar.xz"
+	TxzExtension    = ".txz"
+	TarZstExtension = ".tar.zst"
+	TzstExtension   = ".tzst"
 )
 
+// IsArchive returns true if the file is the archive that can be uncompressed by Uncompress.
+func IsArchive(file string) bool {
+	return archiveExtension(file) != ""
+}
+
+// IsGzippedFile returns true if the file is a gzipped single file rather than a gzipped tarball, e.g. 'greptime.gz'.
+func IsGzippedFile(file string) bool {
+	return archiveExtension(file) == GzExtension
+}
+
+// archiveExtension returns the extension of the archive, or empty if the file is not an archive.
+func archiveExtension(file string) string {
+	for _, ext := range []string{
+		TarGzExtension, TarXzExtension, TarZstExtension,
+		ZipExtension, TgzExtension, GzExtension, TarExtension, TxzExtension, TzstExtension,
+	} {
+		if strings.HasSuffix(file, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
 // Uncompress uncompresses the file to the destination directory.
+// The '.gz' file that is not a tarball is regarded as a gzipped single-file binary, and the file that is not an archive is regarded as a single-file binary, and it's copied to the destination directory as an executable.
+// The entries that escape the destination directory by path traversal or symlinks are rejected, and the file modes are kept.
 func Uncompress(file, dst string) error {
-	fileType := path.Ext(file)
-	switch fileType {
+	if err := EnsureDir(dst); err != nil {
+		return err
+	}
+
+	switch archiveExtension(file) {
 	case ZipExtension:
 		return unzip(file, dst)
-	case TgzExtension, GzExtension, TarGzExtension:
-		return untar(file, dst)
+	case TgzExtension, TarGzExtension:
+		return untar(file, dst, func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		})
+	case GzExtension:
+		return gunzip(file, dst)
+	case TarXzExtension, TxzExtension:
+		return untar(file, dst, func(r io.Reader) (io.Reader, error) {
+			return xz.NewReader(r)
+		})
+	case TarZstExtension, TzstExtension:
+		return untar(file, dst, func(r io.Reader) (io.Reader, error) {
+			decoder, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		})
+	case TarExtension:
+		return untar(file, dst, func(r io.Reader) (io.Reader, error) {
+			return r, nil
+		})
 	default:
-		return fmt.Errorf("unsupported file type: %s", fileType)
+		return copyFileWithMode(file, filepath.Join(dst, filepath.Base(file)), 0755)
+	}
+}
+
+// gunzip decompresses the gzipped single-file binary to the destination directory as an executable,
+// the file is named without the '.gz' extension.
+func gunzip(file, dst string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
 	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return writeFileInArchive(dst, filepath.Join(dst, strings.TrimSuffix(filepath.Base(file), GzExtension)), r, 0755)
 }
 
 func unzip(file, dst string) error {
@@ -118,55 +214,67 @@ func unzip(file, dst string) error {
 	defer archive.Close()
 
 	for _, f := range archive.File {
-		filePath := filepath.Join(dst, f.Name)
-
-		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(filePath, os.ModePerm); err != nil {
-				return err
-			}
-			continue
-		}
-
-		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-			return err
-		}
-
-		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+		filePath, err := archiveEntryPath(dst, f.Name)
 		if err != nil {
 			return err
 		}
 
-		fileInArchive, err := f.Open()
-		if err != nil {
-			return err
-		}
-
-		if _, err := io.Copy(dstFile, fileInArchive); err != nil {
-			return err
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := mkdirInArchive(dst, filePath, mode.Perm()); err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			target, err := readZipFile(f)
+			if err != nil {
+				return err
+			}
+			if err := symlinkInArchive(dst, filePath, string(target)); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			fileInArchive, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = writeFileInArchive(dst, filePath, fileInArchive, mode.Perm())
+			fileInArchive.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported file '%s' of mode '%s' in archive", f.Name, mode)
 		}
+	}
 
-		if err := dstFile.Close(); err != nil {
-			return err
-		}
+	return nil
+}
 
-		if err := fileInArchive.Close(); err != nil {
-			return err
-		}
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
 	}
+	defer r.Close()
 
-	return nil
+	return io.ReadAll(r)
 }
 
-func untar(file, dst string) error {
-	data, err := os.ReadFile(file)
+func untar(file, dst string, decompress func(io.Reader) (io.Reader, error)) error {
+	f, err := os.Open(file)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
-	stream, err := gzip.NewReader(bytes.NewReader(data))
+	stream, err := decompress(f)
 	if err != nil {
 		return err
 	}
+	if closer, ok := stream.(io.Closer); ok {
+		defer closer.Close()
+	}
 
 	tarReader := tar.NewReader(stream)
 
@@ -181,26 +289,37 @@ func untar(file, dst string) error {
 			return err
 		}
 
+		filePath, err := archiveEntryPath(dst, header.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := header.FileInfo().Mode()
 		switch header.Typeflag {
 		case tar.TypeReg:
-			filePath := path.Join(dst, header.Name)
-			outFile, err := os.Create(filePath)
-			if err != nil && !os.IsExist(err) {
+			if err := writeFileInArchive(dst, filePath, tarReader, mode.Perm()); err != nil {
 				return err
 			}
-			if _, err := io.Copy(outFile, tarReader); err != nil {
+		case tar.TypeDir:
+			if err := mkdirInArchive(dst, filePath, mode.Perm()); err != nil {
 				return err
 			}
-
-			if err := os.Chmod(filePath, os.FileMode(header.Mode)); err != nil {
+		case tar.TypeSymlink:
+			if err := symlinkInArchive(dst, filePath, header.Linkname); err != nil {
 				return err
 			}
-
-			if err := outFile.Close(); err != nil {
+		case tar.TypeLink:
+			target, err := archiveEntryPath(dst, header.Linkname)
+			if err != nil {
 				return err
 			}
-		case tar.TypeDir:
-			if err := os.Mkdir(path.Join(dst, header.Name), 0755); err != nil && !os.IsExist(err) {
+			if err := checkParentsInArchive(dst, target); err != nil {
+				return err
+			}
+			if err := checkParentsInArchive(dst, filePath); err != nil {
+				return err
+			}
+			if err := os.Link(target, filePath); err != nil {
 				return err
 			}
 		default:
@@ -210,3 +329,115 @@ func untar(file, dst string) error {
 
 	return nil
 }
+
+// archiveEntryPath returns the path of the entry in the destination directory.
+// It rejects the absolute path and the path that escapes the destination directory, e.g. '../../etc/passwd'.
+func archiveEntryPath(dst, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("illegal absolute path '%s' in archive", name)
+	}
+
+	filePath := filepath.Join(dst, name)
+	if !isWithinDir(dst, filePath) {
+		return "", fmt.Errorf("illegal path '%s' in archive, it escapes the destination", name)
+	}
+
+	return filePath, nil
+}
+
+// symlinkInArchive creates the symlink whose target must be within the destination directory.
+func symlinkInArchive(dst, filePath, target string) error {
+	if filepath.IsAbs(target) || !isWithinDir(dst, filepath.Join(filepath.Dir(filePath), target)) {
+		return fmt.Errorf("illegal symlink '%s' -> '%s' in archive, it escapes the destination", filePath, target)
+	}
+	if err := checkParentsInArchive(dst, filePath); err != nil {
+		return err
+	}
+
+	return os.Symlink(target, filePath)
+}
+
+func mkdirInArchive(dst, dir string, perm os.FileMode) error {
+	if err := checkParentsInArchive(dst, dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Make sure the directory is still writable for the following entries.
+	return os.Chmod(dir, perm|0700)
+}
+
+func writeFileInArchive(dst, filePath string, r io.Reader, perm os.FileMode) error {
+	if err := checkParentsInArchive(dst, filePath); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	// Remove the existing file or symlink, so that the file is never written through the symlink.
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(outFile, r); err != nil {
+		outFile.Close()
+		return err
+	}
+	if err := outFile.Close(); err != nil {
+		return err
+	}
+
+	// The mode of the created file is affected by umask.
+	return os.Chmod(filePath, perm)
+}
+
+// checkParentsInArchive rejects the path whose parent directories in the destination directory contain symlinks,
+// so that the entries can not escape the destination directory through the symlinks in the same archive.
+func checkParentsInArchive(dst, filePath string) error {
+	rel, err := filepath.Rel(dst, filepath.Dir(filePath))
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return nil
+	}
+
+	current := dst
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("illegal path '%s' in archive, its parent '%s' is a symlink", filePath, current)
+		}
+	}
+
+	return nil
+}
+
+func isWithinDir(dir, filePath string) bool {
+	rel, err := filepath.Rel(dir, filePath)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func copyFileWithMode(src, dst string, perm os.FileMode) error {
+	if err := CopyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Chmod(dst, perm)
+}
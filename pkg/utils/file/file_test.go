@@ -17,9 +17,17 @@
 package file
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
 )
 
 func TestUncompress(t *testing.T) {
@@ -59,3 +67,191 @@ func TestUncompress(t *testing.T) {
 		}
 	}
 }
+
+func TestUncompressFormats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	entries := []tar.Header{
+		{Name: "pkg/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "pkg/bin/greptime", Typeflag: tar.TypeReg, Mode: 0750},
+		{Name: "pkg/README", Typeflag: tar.TypeReg, Mode: 0600},
+		{Name: "pkg/greptime-link", Typeflag: tar.TypeSymlink, Linkname: "bin/greptime"},
+	}
+
+	tests := []struct {
+		filename string
+		compress func(w io.Writer) (io.WriteCloser, error)
+	}{
+		{"test.tar.gz", func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }},
+		{"test.tar.xz", func(w io.Writer) (io.WriteCloser, error) { return xz.NewWriter(w) }},
+		{"test.tar.zst", func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }},
+		{"test.tar", nil},
+	}
+	for _, test := range tests {
+		archive := filepath.Join(tempDir, test.filename)
+		writeTestArchive(t, archive, test.compress, entries)
+
+		dst := filepath.Join(tempDir, test.filename+"-output")
+		if err := Uncompress(archive, dst); err != nil {
+			t.Errorf("uncompress file '%s': %v", archive, err)
+			continue
+		}
+
+		for name, mode := range map[string]os.FileMode{"pkg/bin/greptime": 0750, "pkg/README": 0600} {
+			info, err := os.Stat(filepath.Join(dst, name))
+			if err != nil {
+				t.Errorf("stat file '%s' of '%s': %v", name, test.filename, err)
+				continue
+			}
+			if info.Mode().Perm() != mode {
+				t.Errorf("the mode of '%s' in '%s' is not '%s': %s", name, test.filename, mode, info.Mode().Perm())
+			}
+		}
+
+		data, err := os.ReadFile(filepath.Join(dst, "pkg/greptime-link"))
+		if err != nil {
+			t.Errorf("read symlink of '%s': %v", test.filename, err)
+		}
+		if string(data) != "pkg/bin/greptime" {
+			t.Errorf("unexpected content of symlink in '%s': %s", test.filename, string(data))
+		}
+	}
+
+	// The single-file binary is copied as an executable.
+	binary := filepath.Join(tempDir, "greptime")
+	if err := os.WriteFile(binary, []byte("binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(tempDir, "binary-output")
+	if err := Uncompress(binary, dst); err != nil {
+		t.Fatalf("uncompress binary: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "greptime"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("the binary is not executable: %s", info.Mode().Perm())
+	}
+
+	// The gzipped single-file binary is decompressed as an executable.
+	gzipped := filepath.Join(tempDir, "etcd.gz")
+	f, err := os.Create(gzipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := gzip.NewWriter(f)
+	if _, err = w.Write([]byte("binary")); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	dst = filepath.Join(tempDir, "gzipped-output")
+	if err = Uncompress(gzipped, dst); err != nil {
+		t.Fatalf("uncompress gzipped binary: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "etcd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "binary" {
+		t.Errorf("unexpected content of gzipped binary: %s", string(data))
+	}
+	if info, err = os.Stat(filepath.Join(dst, "etcd")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("the gzipped binary is not executable: %v", err)
+	}
+}
+
+func TestUncompressUnsafeEntries(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name    string
+		entries []tar.Header
+	}{
+		{"path-traversal", []tar.Header{
+			{Name: "../../evil", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"absolute-path", []tar.Header{
+			{Name: "/tmp/evil", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"symlink-escape", []tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"},
+		}},
+		{"absolute-symlink", []tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+		}},
+		{"write-through-symlink", []tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "link/evil", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"hardlink-escape", []tar.Header{
+			{Name: "link", Typeflag: tar.TypeLink, Linkname: "../../etc/passwd"},
+		}},
+	}
+	for _, test := range tests {
+		archive := filepath.Join(tempDir, test.name+".tar.gz")
+		writeTestArchive(t, archive, func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }, test.entries)
+
+		dst := filepath.Join(tempDir, test.name, "output")
+		if err := Uncompress(archive, dst); err == nil {
+			t.Errorf("expected error for the archive '%s'", test.name)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "evil")); err == nil {
+			t.Errorf("the file escapes the destination by the archive '%s'", test.name)
+		}
+	}
+}
+
+// writeTestArchive writes the tar archive with the entries, the content of regular file is its name.
+func writeTestArchive(t *testing.T, file string, compress func(w io.Writer) (io.WriteCloser, error), entries []tar.Header) {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var cw io.WriteCloser
+	if compress != nil {
+		var err error
+		if cw, err = compress(&buf); err != nil {
+			t.Fatal(err)
+		}
+		w = cw
+	}
+
+	tw := tar.NewWriter(w)
+	for _, entry := range entries {
+		entry := entry
+		var content []byte
+		if entry.Typeflag == tar.TypeReg {
+			content = []byte(entry.Name)
+			entry.Size = int64(len(content))
+		}
+		if err := tw.WriteHeader(&entry); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if cw != nil {
+		if err := cw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
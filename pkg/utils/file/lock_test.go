@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTryLockFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	lockFile := filepath.Join(tempDir, ".lock")
+	open := func() *os.File {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	owner := open()
+	defer owner.Close()
+	if err := TryLockFile(owner); err != nil {
+		t.Fatalf("failed to lock the free file: %v", err)
+	}
+
+	other := open()
+	defer other.Close()
+	if err := TryLockFile(other); err != ErrLocked {
+		t.Fatalf("expected ErrLocked for the locked file, got %v", err)
+	}
+
+	if err := UnlockFile(owner); err != nil {
+		t.Fatalf("failed to unlock: %v", err)
+	}
+	if err := TryLockFile(other); err != nil {
+		t.Fatalf("failed to lock the unlocked file: %v", err)
+	}
+
+	if err := RemoveLockFile(other); err != nil {
+		t.Fatalf("failed to remove the lock file: %v", err)
+	}
+	if _, err := os.Stat(lockFile); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !windows
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// LockFile acquires the exclusive advisory lock of the opened file, it blocks until the lock is acquired.
+// The lock is released automatically if the process exits.
+func LockFile(f *os.File) error {
+	return flock(f, syscall.LOCK_EX)
+}
+
+// TryLockFile acquires the exclusive advisory lock of the opened file without blocking.
+// It returns ErrLocked if the lock is held by another process.
+// The lock is released automatically if the process exits.
+func TryLockFile(f *os.File) error {
+	if err := flock(f, syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if err == syscall.EWOULDBLOCK {
+			return ErrLocked
+		}
+		return err
+	}
+	return nil
+}
+
+// UnlockFile releases the lock of the file that is acquired by TryLockFile.
+func UnlockFile(f *os.File) error {
+	return flock(f, syscall.LOCK_UN)
+}
+
+// RemoveLockFile removes the locked file, then releases the lock and closes the file.
+// The file is removed before unlocking, so that it will not remove the file that is locked by the next owner.
+func RemoveLockFile(f *os.File) error {
+	if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := UnlockFile(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// flock applies or removes the advisory lock on the file, it retries if it's interrupted.
+func flock(f *os.File, how int) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//go:build windows
+
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// LockFile acquires the exclusive lock of the opened file, it blocks until the lock is acquired.
+// The lock is released automatically if the process exits.
+func LockFile(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
+
+// TryLockFile acquires the exclusive lock of the opened file without blocking.
+// It returns ErrLocked if the lock is held by another process.
+// The lock is released automatically if the process exits.
+func TryLockFile(f *os.File) error {
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
+		0, 1, 0, &windows.Overlapped{})
+	if err == windows.ERROR_LOCK_VIOLATION {
+		return ErrLocked
+	}
+	return err
+}
+
+// UnlockFile releases the lock of the file that is acquired by TryLockFile.
+func UnlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
+
+// RemoveLockFile releases the lock and closes the file, then removes it.
+// The opened file can't be removed on Windows, so it's removed after closing. If the next owner
+// has opened it in the meantime, the removal fails and the file is kept for the next owner.
+func RemoveLockFile(f *os.File) error {
+	if err := UnlockFile(f); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(f.Name())
+	return nil
+}
@@ -19,11 +19,11 @@ package file
 import (
 	"bytes"
 
-	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 )
 
-// MergeYAML merges two yaml files from src to dst, the src yaml will override dst yaml if the same key exists.
+// MergeYAML merges two yaml files from src to dst recursively, the src yaml will override dst yaml if the same key exists.
+// The nested maps will be merged, and the other values such as lists will be replaced.
 func MergeYAML(dst, src []byte) ([]byte, error) {
 	map1 := map[string]interface{}{}
 	map2 := map[string]interface{}{}
@@ -36,13 +36,13 @@ func MergeYAML(dst, src []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	maps.Copy(map2, map1)
+	merged := MergeMaps(map2, map1)
 
 	buf := bytes.NewBuffer([]byte{})
 	encoder := yaml.NewEncoder(buf)
 	encoder.SetIndent(2)
 
-	if err := encoder.Encode(map2); err != nil {
+	if err := encoder.Encode(merged); err != nil {
 		return nil, err
 	}
 
@@ -52,3 +52,24 @@ func MergeYAML(dst, src []byte) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// MergeMaps merges two maps recursively, the values in b override the ones in a.
+// The function is copied from 'helm/helm/pkg/cli/values/options.go'.
+func MergeMaps(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if v, ok := v.(map[string]interface{}); ok {
+			if bv, ok := out[k]; ok {
+				if bv, ok := bv.(map[string]interface{}); ok {
+					out[k] = MergeMaps(bv, v)
+					continue
+				}
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
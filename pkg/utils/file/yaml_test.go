@@ -63,6 +63,30 @@ i:
   j: j
 k:
   l: l
+`,
+		},
+		{
+			name: "nested",
+			yaml1: `
+storage:
+  type: s3
+  s3:
+    bucket: base
+    region: us-east-1
+replicas: 1
+`,
+			yaml2: `
+storage:
+  s3:
+    bucket: prod
+replicas: 3
+`,
+			want: `replicas: 3
+storage:
+  s3:
+    bucket: prod
+    region: us-east-1
+  type: s3
 `,
 		},
 	}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package home
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// BaseDir is the working directory of gtctl in its home directory.
+	BaseDir = ".gtctl"
+
+	// DirEnv is the environment variable of the home directory of gtctl,
+	// it's used when the home directory is not specified and defaults to the home directory of current user.
+	DirEnv = "GTCTL_HOME"
+)
+
+// Dir returns the absolute home directory of gtctl.
+// If homeDir is empty, it uses 'GTCTL_HOME' or the home directory of current user.
+func Dir(homeDir string) (string, error) {
+	if homeDir == "" {
+		homeDir = os.Getenv(DirEnv)
+	}
+	if homeDir == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		homeDir = dir
+	}
+
+	return filepath.Abs(homeDir)
+}
+
+// WorkingDir returns the working directory of gtctl, that is '${HomeDir}/${BaseDir}'.
+func WorkingDir(homeDir string) (string, error) {
+	dir, err := Dir(homeDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, BaseDir), nil
+}
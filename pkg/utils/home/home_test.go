@@ -0,0 +1,54 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package home
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkingDir(t *testing.T) {
+	t.Setenv(DirEnv, "/tmp/gtctl-home")
+	dir, err := WorkingDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("/tmp/gtctl-home", BaseDir); dir != want {
+		t.Errorf("expected working dir '%s', got '%s'", want, dir)
+	}
+
+	// The home directory in arguments takes precedence over 'GTCTL_HOME'.
+	if dir, err = WorkingDir("/opt/gtctl"); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("/opt/gtctl", BaseDir); dir != want {
+		t.Errorf("expected working dir '%s', got '%s'", want, dir)
+	}
+
+	t.Setenv(DirEnv, "")
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	if dir, err = WorkingDir(""); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(userHome, BaseDir); dir != want {
+		t.Errorf("expected working dir '%s', got '%s'", want, dir)
+	}
+}
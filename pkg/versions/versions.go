@@ -0,0 +1,314 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package versions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/config"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+	"github.com/GreptimeTeam/gtctl/pkg/status"
+	fileutils "github.com/GreptimeTeam/gtctl/pkg/utils/file"
+)
+
+// Manager manages the installed versions of binaries in the artifacts directory of gtctl.
+type Manager struct {
+	am     artifacts.Manager
+	mm     metadata.Manager
+	logger logger.Logger
+}
+
+// Installed is an installed version of binary.
+type Installed struct {
+	Version string
+
+	// Path is the path of the executable binary.
+	Path string
+}
+
+// InstallOptions is the options for installing a binary.
+type InstallOptions struct {
+	// FromCNRegion indicates whether to download the binary from CN region.
+	FromCNRegion bool
+
+	// Spinner is used to show the progress of downloading, it can be nil.
+	Spinner *status.Spinner
+}
+
+// NewManager creates a version manager with artifacts manager and metadata manager.
+func NewManager(l logger.Logger, am artifacts.Manager, mm metadata.Manager) *Manager {
+	return &Manager{
+		am:     am,
+		mm:     mm,
+		logger: l,
+	}
+}
+
+// Install downloads and installs the version of binary, the cached package will be reused.
+func (m *Manager) Install(ctx context.Context, name, version string, opts *InstallOptions) (*Installed, error) {
+	src, err := m.am.NewSource(name, version, artifacts.ArtifactTypeBinary, opts.FromCNRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	destDir, err := m.mm.AllocateArtifactFilePath(src, false)
+	if err != nil {
+		return nil, err
+	}
+
+	installDir, err := m.mm.AllocateArtifactFilePath(src, true)
+	if err != nil {
+		return nil, err
+	}
+
+	binPath, err := m.am.DownloadTo(ctx, src, destDir, &artifacts.DownloadOptions{
+		EnableCache:      true,
+		BinaryInstallDir: installDir,
+		Spinner:          opts.Spinner,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Installed{Version: src.Version, Path: binPath}, nil
+}
+
+// List lists the installed versions of binary, the newer versions come first.
+func (m *Manager) List(name string) ([]*Installed, error) {
+	dir, err := m.binaryDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var installed []*Installed
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		// The version that only has the downloaded package is not installed.
+		binPath, err := m.binaryPath(name, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if exist, _ := fileutils.IsFileExists(binPath); !exist {
+			continue
+		}
+
+		installed = append(installed, &Installed{Version: entry.Name(), Path: binPath})
+	}
+
+	sort.SliceStable(installed, func(i, j int) bool {
+		return newer(installed[i].Version, installed[j].Version)
+	})
+
+	return installed, nil
+}
+
+// ListRemote lists the released versions of binary, the newer versions come first.
+func (m *Manager) ListRemote(ctx context.Context, name string) ([]string, error) {
+	return m.am.ListVersions(ctx, name, artifacts.ArtifactTypeBinary)
+}
+
+// Get returns the installed version of binary, it returns error if the version is not installed.
+func (m *Manager) Get(name, version string) (*Installed, error) {
+	binPath, err := m.binaryPath(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	exist, err := fileutils.IsFileExists(binPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, fmt.Errorf("%s '%s' is not installed", name, version)
+	}
+
+	return &Installed{Version: version, Path: binPath}, nil
+}
+
+// Prune removes the installed versions of binary that are not referenced by any bare-metal cluster.
+// The versions in keep will be always retained. It returns the removed versions.
+func (m *Manager) Prune(name string, keep ...string) ([]string, error) {
+	installed, err := m.List(name)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced, err := m.referencedVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range keep {
+		referenced[version] = true
+	}
+
+	// The cluster that uses the 'latest' version may run any installed version,
+	// so the newest one is retained conservatively.
+	if referenced[artifacts.LatestVersionTag] && len(installed) > 0 {
+		referenced[installed[0].Version] = true
+	}
+
+	dir, err := m.binaryDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, v := range installed {
+		if referenced[v.Version] {
+			continue
+		}
+
+		m.logger.V(3).Infof("Removing %s '%s'", name, v.Version)
+		if err := os.RemoveAll(filepath.Join(dir, v.Version)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, v.Version)
+	}
+
+	return removed, nil
+}
+
+// referencedVersions returns the versions of binary that are referenced by the bare-metal clusters,
+// which are in the cluster registry or have the metadata in the working directory.
+func (m *Manager) referencedVersions(name string) (map[string]bool, error) {
+	referenced := make(map[string]bool)
+
+	records, err := m.mm.ListClusterRecords()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.Mode != metadata.ClusterModeBareMetal {
+			continue
+		}
+
+		// The versions of the cluster that is being created are not resolved yet.
+		version, ok := record.Versions[name]
+		if !ok || len(version) == 0 {
+			version = artifacts.LatestVersionTag
+		}
+		// The local binary is recorded as its path.
+		if strings.ContainsAny(version, `/\`) && !artifacts.IsOCIReference(version) {
+			continue
+		}
+		referenced[version] = true
+	}
+
+	workingDir := m.mm.GetWorkingDir()
+	entries, err := os.ReadDir(workingDir)
+	if os.IsNotExist(err) {
+		return referenced, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		// The metadata of cluster is stored in '${ClusterName}/${ClusterName}.yaml'.
+		data, err := os.ReadFile(filepath.Join(workingDir, entry.Name(), fmt.Sprintf("%s.yaml", entry.Name())))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var cluster config.BareMetalClusterMetadata
+		if err := yaml.Unmarshal(data, &cluster); err != nil || cluster.Config == nil {
+			m.logger.V(3).Infof("Skip the invalid metadata of cluster '%s'", entry.Name())
+			continue
+		}
+
+		var artifact *config.Artifact
+		switch {
+		case name == artifacts.GreptimeBinName && cluster.Config.Cluster != nil:
+			artifact = cluster.Config.Cluster.Artifact
+		case name == artifacts.EtcdBinName && cluster.Config.Etcd != nil:
+			artifact = cluster.Config.Etcd.Artifact
+		}
+		if artifact == nil || len(artifact.Local) > 0 {
+			continue
+		}
+
+		version := artifact.Version
+		if len(version) == 0 {
+			version = artifacts.LatestVersionTag
+		}
+		referenced[version] = true
+	}
+
+	return referenced, nil
+}
+
+// binaryDir returns the directory that holds all the versions of binary.
+func (m *Manager) binaryDir(name string) (string, error) {
+	// The install dir is '${binaryDir}/${Version}/bin', so it's '${binaryDir}/bin' if the version is empty.
+	installDir, err := m.mm.AllocateArtifactFilePath(&artifacts.Source{Name: name, Type: artifacts.ArtifactTypeBinary}, true)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(installDir), nil
+}
+
+func (m *Manager) binaryPath(name, version string) (string, error) {
+	installDir, err := m.mm.AllocateArtifactFilePath(&artifacts.Source{Name: name, Version: version, Type: artifacts.ArtifactTypeBinary}, true)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(installDir, name), nil
+}
+
+// newer returns true if v1 is newer than v2. The invalid semantic versions are always older.
+func newer(v1, v2 string) bool {
+	semV1, err1 := semver.NewVersion(v1)
+	semV2, err2 := semver.NewVersion(v2)
+	switch {
+	case err1 != nil && err2 != nil:
+		return v1 > v2
+	case err1 != nil:
+		return false
+	case err2 != nil:
+		return true
+	default:
+		return semV1.GreaterThan(semV2)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2023 Greptime Team
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package versions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/log"
+
+	"github.com/GreptimeTeam/gtctl/pkg/artifacts"
+	"github.com/GreptimeTeam/gtctl/pkg/logger"
+	"github.com/GreptimeTeam/gtctl/pkg/metadata"
+)
+
+func TestListAndPrune(t *testing.T) {
+	tempDir, err := os.MkdirTemp("/tmp", "gtctl-ut-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	mm, err := metadata.New(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(logger.New(os.Stdout, log.Level(4), logger.WithColored()), nil, mm)
+
+	// The 'v0.2.0' only has the downloaded package, so it's not installed.
+	binariesDir := filepath.Join(mm.GetWorkingDir(), "artifacts", "binaries", artifacts.GreptimeBinName)
+	for _, version := range []string{"v0.3.2", "v0.4.0", "v0.4.0-nightly-20230807", "v0.1.0"} {
+		installBinary(t, filepath.Join(binariesDir, version, "bin", artifacts.GreptimeBinName))
+	}
+	if err := os.MkdirAll(filepath.Join(binariesDir, "v0.2.0", "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	installed, err := m.List(artifacts.GreptimeBinName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range installed {
+		got = append(got, v.Version)
+	}
+	expected := []string{"v0.4.0", "v0.4.0-nightly-20230807", "v0.3.2", "v0.1.0"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected installed versions %v, got %v", expected, got)
+	}
+
+	if _, err := m.Get(artifacts.GreptimeBinName, "v0.2.0"); err == nil {
+		t.Errorf("expected error for the version that is not installed")
+	}
+
+	// The cluster 'mycluster' references 'v0.3.2'.
+	clusterDir := filepath.Join(mm.GetWorkingDir(), "mycluster")
+	if err := os.MkdirAll(clusterDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	clusterMetadata := `
+config:
+  cluster:
+    artifact:
+      version: v0.3.2
+  etcd:
+    artifact:
+      version: v3.5.7
+`
+	if err := os.WriteFile(filepath.Join(clusterDir, "mycluster.yaml"), []byte(clusterMetadata), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The registered cluster 'registered' references 'v0.4.0-nightly-20230807' without the metadata directory,
+	// and the versions of the cluster in Kubernetes are not the versions of binaries.
+	if err := mm.RegisterCluster(&metadata.ClusterRecord{
+		Name:     "registered",
+		Mode:     metadata.ClusterModeBareMetal,
+		Versions: map[string]string{artifacts.GreptimeBinName: "v0.4.0-nightly-20230807"},
+		State:    metadata.ClusterStateStopped,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mm.RegisterCluster(&metadata.ClusterRecord{
+		Name:      "mycluster",
+		Mode:      metadata.ClusterModeKubernetes,
+		Namespace: "default",
+		Versions:  map[string]string{artifacts.GreptimeBinName: "v0.4.0"},
+		State:     metadata.ClusterStateRunning,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := m.Prune(artifacts.GreptimeBinName, "v0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"v0.4.0"}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("expected removed versions %v, got %v", expected, removed)
+	}
+
+	for _, version := range []string{"v0.3.2", "v0.4.0-nightly-20230807", "v0.1.0"} {
+		if _, err := m.Get(artifacts.GreptimeBinName, version); err != nil {
+			t.Errorf("expected '%s' to be retained: %v", version, err)
+		}
+	}
+}
+
+func installBinary(t *testing.T, binPath string) {
+	if err := os.MkdirAll(filepath.Dir(binPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}